@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"overlay/internal/config"
+	"overlay/internal/gsi"
+	"overlay/internal/setup"
+)
+
+// doctor prints the setup checklist and exits 1 if anything fails.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("config: %v (using defaults)", err)
+	}
+
+	checks := setup.Run(gsi.NewCfgOptions(cfg.GSIAddr, cfg.GSIToken, cfg.GSISections))
+	for _, c := range checks {
+		mark := "ok  "
+		if !c.OK {
+			mark = "FAIL"
+		}
+		fmt.Printf("[%s] %-24s %s\n", mark, c.Name, c.Detail)
+		if !c.OK && c.Fix != "" {
+			fmt.Printf("       fix: %s\n", c.Fix)
+		}
+	}
+
+	if n := setup.Failed(checks); n > 0 {
+		fmt.Printf("\n%d of %d checks failed\n", n, len(checks))
+		os.Exit(1)
+	}
+	fmt.Println("\nall checks passed")
+}
@@ -3,13 +3,20 @@ package main
 import (
 	"log"
 
+	"overlay/internal/config"
 	"overlay/internal/dotaplus"
+	"overlay/internal/gsi"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
-	app := dotaplus.New()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("config: %v (using defaults)", err)
+	}
+
+	app := dotaplus.New(gsi.DialAddr(cfg.GSIAddr))
 
 	ebiten.SetWindowSize(dotaplus.ViewWidth, dotaplus.ViewHeight)
 	ebiten.SetWindowTitle("Dota Plus")
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"overlay/internal/config"
+	"overlay/internal/gsi"
+	"overlay/internal/paths"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("config: %v (using defaults)", err)
+	}
+
+	dotaDir := flag.String("dota", "", "Dota install folder (\".../common/dota 2 beta\"); found through Steam by default")
+	check := flag.Bool("check", false, "only report whether the installed cfg is current; exits 1 if not")
+	printOnly := flag.Bool("print", false, "print the cfg instead of installing it")
+	flag.Parse()
+
+	opts := gsi.NewCfgOptions(cfg.GSIAddr, cfg.GSIToken, cfg.GSISections)
+
+	if *printOnly {
+		os.Stdout.Write(gsi.RenderCfg(opts))
+		return
+	}
+
+	if *dotaDir == "" {
+		*dotaDir, err = paths.FindDotaDir()
+		if err != nil {
+			log.Fatal("Dota not found in any Steam library; pass -dota")
+		}
+	}
+	path := filepath.Join(gsi.CfgDir(*dotaDir), gsi.CfgFileName)
+
+	if *check {
+		diffs, err := gsi.CheckCfg(path, opts)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			fmt.Printf("%s: not installed\n", path)
+			os.Exit(1)
+		case err != nil:
+			log.Fatal(err)
+		case len(diffs) > 0:
+			fmt.Printf("%s: out of date\n", path)
+			for _, d := range diffs {
+				fmt.Println("  " + d)
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("%s: up to date\n", path)
+		return
+	}
+
+	path, written, err := gsi.InstallCfg(gsi.CfgDir(*dotaDir), opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if written {
+		fmt.Printf("installed %s; restart Dota to pick it up\n", path)
+	} else {
+		fmt.Printf("%s is already up to date\n", path)
+	}
+}
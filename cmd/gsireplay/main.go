@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"time"
+
+	"overlay/internal/config"
+	"overlay/internal/gsi"
+	"overlay/internal/state"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("config: %v (using defaults)", err)
+	}
+
+	file := flag.String("file", "gsi_log.txt", "GSI recording to replay")
+	target := flag.String("url", gsi.ServerURL(cfg.GSIAddr), "GSI endpoint to post to")
+	speed := flag.Float64("speed", 1, "playback speed; 0 sends everything at once")
+	token := flag.String("token", cfg.GSIToken, "auth token to put into every payload")
+	direct := flag.Bool("direct", false, "feed an in-process server instead of posting, and print the final snapshot")
+	flag.Parse()
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	records, err := gsi.ReadLog(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(records) == 0 {
+		log.Fatalf("no payloads in %s", *file)
+	}
+	log.Printf("replaying %d payloads from %s (%s of play)", len(records), *file,
+		records[len(records)-1].At.Sub(records[0].At).Round(time.Second))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	var handler http.Handler
+	if *direct {
+		st := state.NewGameState(nil, nil)
+		srv := gsi.NewServer(st, *token, nil, nil)
+		srv.SetRawLog("")
+		srv.Subscribe(func(ev gsi.Event) {
+			fmt.Printf("[%5ds] %s\n", ev.GameTime, ev)
+		})
+		handler = srv.Handler()
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	sent, rejected := 0, 0
+	err = gsi.Replay(ctx, records, *speed, func(rec gsi.LogRecord) error {
+		body := rec.Body
+		if *token != "" {
+			body = gsi.WithToken(body, *token)
+		}
+
+		status, err := post(client, handler, *target, body)
+		if err != nil {
+			return err
+		}
+		sent++
+		if status != http.StatusOK {
+			rejected++
+			log.Printf("%s: status %d", rec.At.Format(time.RFC3339), status)
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Fatal(err)
+	}
+	log.Printf("sent %d payloads, %d rejected", sent, rejected)
+
+	if handler != nil {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot", nil))
+		var out bytes.Buffer
+		if err := json.Indent(&out, rec.Body.Bytes(), "", "  "); err == nil {
+			out.WriteTo(os.Stdout)
+		}
+	}
+}
+
+func post(client *http.Client, handler http.Handler, target string, body []byte) (int, error) {
+	if handler != nil {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code, nil
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Keeps a running overlay from appending the replay to gsi_log.txt.
+	req.Header.Set(gsi.ReplayHeader, "1")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -3,24 +3,53 @@ package main
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"overlay/internal/app"
+	"overlay/internal/config"
 	"overlay/internal/gsi"
 	"overlay/internal/opendota"
 	"overlay/internal/parser"
 	"overlay/internal/paths"
+	"overlay/internal/setup"
 	"overlay/internal/state"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// analyzeWorkers bounds concurrent matchup lookups per analysis; the
+// OpenDota client rate-limits on top of that.
+const analyzeWorkers = 3
+
+// The imported hero pool is the poolSize most played heroes with at least
+// poolMinGames games, refreshed after poolMaxAge.
+const (
+	poolSize     = 20
+	poolMinGames = 5
+	poolMaxAge   = 7 * 24 * time.Hour
+)
+
+const setupRecheck = 30 * time.Second
+
+// defaultWindowsLogPath is followed on Windows when no Steam library with
+// Dota is found.
+const defaultWindowsLogPath = `C:\Program Files (x86)\Steam\steamapps\common\dota 2 beta\game\dota\console.log`
+
 func main() {
+	consoleReplay := flag.String("console-replay", "", "replay a recorded console log (e.g. log_dota.txt) instead of tailing console.log")
+	replaySpeed := flag.Float64("replay-speed", 1, "playback speed for -console-replay; 0 replays without waiting")
+	consoleNotify := flag.Bool("console-notify", parser.NotifyByDefault, "wait for file change notifications instead of polling console.log every 100ms")
+	flag.Parse()
+
 	st := state.NewGameState(
 		map[string]int{
 			"pudge":          14,
@@ -36,12 +65,87 @@ func main() {
 		},
 	)
 
-	logPath := `C:\Program Files (x86)\Steam\steamapps\common\dota 2 beta\game\dota\console.log`
-	if autoPath, err := paths.FindDotaLogPath(); err == nil {
-		logPath = filepath.Join(filepath.Dir(autoPath), "console.log")
+	logPath, err := paths.DotaConsoleLogPath()
+	if err != nil && runtime.GOOS == "windows" {
+		logPath = defaultWindowsLogPath
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Printf("config: %v (using defaults)", err)
+	}
+
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		cacheDir = filepath.Join(os.TempDir(), "dota-overlay", "cache")
+	}
+	client := opendota.NewCachedClient(
+		opendota.NewClient(os.Getenv("OPENDOTA_API_KEY")),
+		opendota.NewCache(cacheDir),
+	)
+
+	sources := []opendota.NamedSource{{Name: "opendota", Source: client}}
+	if path, err := config.ResolveDataPath(cfg.MatchupsPath); err != nil {
+		log.Printf("offline matchups: %v", err)
+	} else if local, err := opendota.LoadLocalMatchups(path); err != nil {
+		log.Printf("offline matchups: %v", err)
+	} else if local.Usable() == 0 {
+		// The matchups.json in the repo is a placeholder with every win rate 0.
+		log.Printf("offline matchups: %s has no win rates, offline fallback off", path)
+	} else {
+		log.Printf("offline matchups: %s (%d heroes with data)", path, local.Usable())
+		sources = append(sources, opendota.NamedSource{Name: "bundled", Source: local})
+	}
+	matchupSource := opendota.NewFallbackSource(st.SetMatchupSource, sources...)
+
+	var synergySource opendota.SynergySource
+	if path, err := config.ResolveDataPath(cfg.DuosPath); err != nil {
+		log.Printf("ally synergy off: %v", err)
+	} else if duos, err := opendota.LoadLocalDuos(path); err != nil {
+		log.Printf("ally synergy off: %v", err)
+	} else {
+		synergySource = duos
+	}
+	weights := opendota.Weights{
+		Counter: cfg.Weights.Counter,
+		Synergy: cfg.Weights.Synergy,
+		Role:    cfg.Weights.Role,
+		Pool:    cfg.Weights.Pool,
+	}
+
+	switch cfg.PoolMode {
+	case opendota.PoolOff, opendota.PoolRestrict, opendota.PoolBoost:
+	default:
+		log.Printf("config: unknown pool_mode %q (using %s)", cfg.PoolMode, opendota.PoolOff)
+		cfg.PoolMode = opendota.PoolOff
+	}
+	if pool, err := config.LoadPool(); err != nil {
+		log.Printf("hero pool: %v", err)
+	} else {
+		st.SetHeroPool(pool.Heroes)
+	}
+
+	position, err := opendota.ParsePosition(cfg.Position)
+	if err != nil {
+		log.Printf("config: %v (using any)", err)
+	}
+
+	scorer, err := opendota.ScorerFor(cfg.Scoring)
+	if err != nil {
+		log.Printf("config: %v (using %s)", err, opendota.DefaultScoring)
+		scorer, _ = opendota.ScorerFor(opendota.DefaultScoring)
 	}
 
-	client := opendota.NewClient(os.Getenv("OPENDOTA_API_KEY"))
+	engine := state.NewCounterEngine(context.Background(), st, state.EngineConfig{
+		Matchups:  matchupSource,
+		BaseRates: matchupSource,
+		Synergy:   synergySource,
+		Scorer:    scorer,
+		Weights:   weights,
+		Position:  position,
+		PoolMode:  cfg.PoolMode,
+		Workers:   analyzeWorkers,
+	})
 
 	go func() {
 		st.SetLoading(true, "Fetching OpenDota heroes...")
@@ -56,8 +160,10 @@ func main() {
 
 		internalToID := make(map[string]int, len(heroes))
 		idToName := make(map[int]string, len(heroes))
+		roles := make(map[int][]string, len(heroes))
 		for _, h := range heroes {
 			idToName[h.ID] = h.LocalizedName
+			roles[h.ID] = h.Roles
 			internal := strings.TrimPrefix(h.Name, "npc_dota_hero_")
 			if internal != "" {
 				internalToID[internal] = h.ID
@@ -65,74 +171,29 @@ func main() {
 		}
 
 		st.SetMappings(internalToID, idToName)
+		st.SetHeroRoles(roles)
 		st.SetLoading(false, "Ready")
 	}()
 
-	onNewHero := func(heroID int) {
-		go func() {
-			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-			defer cancel()
-
-			matchups, err := client.GetHeroMatchups(ctx, heroID)
-			if err != nil {
-				st.SetStatus("OpenDota error: " + err.Error())
-				return
-			}
-
-			counters := opendota.CalculateCounters(matchups, 20, 5)
-			picks := make([]state.CounterPick, 0, len(counters))
-			for _, c := range counters {
-				picks = append(picks, state.CounterPick{
-					HeroID:  c.HeroID,
-					Games:   c.Games,
-					WinRate: c.WinRate,
-				})
-			}
-			st.SetHeroCounters(heroID, picks)
-
-			enemies := st.EnemyHeroes()
-			results, err := opendota.AnalyzeCounters(
-				enemies,
-				func(enemyID int) ([]opendota.HeroMatchup, error) {
-					reqCtx, reqCancel := context.WithTimeout(context.Background(), 10*time.Second)
-					defer reqCancel()
-					return client.GetHeroMatchups(reqCtx, enemyID)
-				},
-				10,
-				100*time.Millisecond,
-			)
-			if err != nil {
-				st.SetStatus("OpenDota analyze error: " + err.Error())
-				return
-			}
-
-			enemySet := make(map[int]struct{}, len(enemies))
-			for _, id := range enemies {
-				enemySet[id] = struct{}{}
-			}
+	go watchSetup(st, gsi.NewCfgOptions(cfg.GSIAddr, cfg.GSIToken, cfg.GSISections))
 
-			best := make([]state.ScoredHero, 0, len(results))
-			for _, r := range results {
-				if _, isEnemy := enemySet[r.HeroID]; isEnemy {
-					continue
-				}
-				best = append(best, state.ScoredHero{HeroID: r.HeroID, Score: r.Score})
-				if len(best) >= 10 {
-					break
-				}
-			}
-			st.SetBestCounters(best)
-		}()
-	}
+	gsiServer := gsi.NewServer(st, cfg.GSIToken, engine.EnemyAdded, func() {
+		st.SetGSISeen(time.Now())
+	})
+	gsiServer.Subscribe(func(ev gsi.Event) {
+		st.AppendOverlayLog("GSI: "+ev.String(), 10)
+		// Allies leave the enemy list, must not show up as picks and change
+		// the synergy half of the score.
+		if ev.Kind == gsi.EventAllyPicked || ev.Kind == gsi.EventDraftTurn {
+			engine.Refresh()
+		}
+		if ev.Kind == gsi.EventPlayerChanged {
+			go importPool(st, client, engine, ev.Name)
+		}
+	})
 
 	go func() {
-		err := gsi.ListenAndServe("127.0.0.1:3001", func(heroID int) {
-			if added := st.AddEnemyHeroByID(heroID); added {
-				onNewHero(heroID)
-			}
-		}, func() {
-			st.SetGSISeen(time.Now())
-		}, st)
+		err := gsiServer.ListenAndServe(cfg.GSIAddr)
 		if err != nil {
 			st.SetStatus("GSI error: " + err.Error())
 		}
@@ -141,11 +202,19 @@ func main() {
 	go func() {
 		st.SetGSIStatus("GSI self-test...")
 		client := &http.Client{Timeout: 2 * time.Second}
-		body := []byte(`{"player":{"team_name":"spectator"},"draft":{"picks_bans":[]}}`)
+		body, _ := json.Marshal(map[string]any{
+			"player": map[string]any{"team_name": "spectator"},
+			"draft":  map[string]any{"picks_bans": []any{}},
+			"auth":   map[string]any{"token": cfg.GSIToken},
+		})
 		for i := 0; i < 5; i++ {
-			resp, err := client.Post("http://127.0.0.1:3001/", "application/json", bytes.NewReader(body))
+			resp, err := client.Post(gsi.ServerURL(cfg.GSIAddr), "application/json", bytes.NewReader(body))
 			if err == nil {
 				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					st.SetGSIStatus("GSI self-test failed: " + resp.Status)
+					return
+				}
 				st.SetGSIStatus("GSI self-test OK")
 				return
 			}
@@ -154,7 +223,17 @@ func main() {
 		st.SetGSIStatus("GSI self-test failed")
 	}()
 
-	go parser.Start(st, logPath, onNewHero)
+	if *consoleReplay != "" {
+		go func() {
+			if err := parser.Replay(context.Background(), st, *consoleReplay, *replaySpeed, engine.EnemyAdded); err != nil {
+				st.SetStatus("Replay error: " + err.Error())
+			}
+		}()
+	} else if logPath == "" {
+		st.SetStatus("console.log: Dota install not found, hero detection off")
+	} else {
+		go parser.Start(st, logPath, parser.FollowOptions{Notify: *consoleNotify}, engine.EnemyAdded)
+	}
 
 	ebiten.SetWindowSize(app.ViewWidth, app.ViewHeight)
 	ebiten.SetWindowFloating(true)
@@ -165,3 +244,53 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// importPool fills the hero pool from the player's OpenDota history unless
+// pool.json already has a current or hand-written one.
+func importPool(st *state.GameState, client *opendota.CachedClient, engine *state.CounterEngine, accountID string) {
+	pool, err := config.LoadPool()
+	if err != nil || !pool.NeedsImport(accountID, poolMaxAge) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	played, err := client.GetPlayerHeroes(ctx, accountID)
+	if err != nil {
+		st.SetStatus("Hero pool import error: " + err.Error())
+		return
+	}
+	heroes := opendota.PoolFromPlayerHeroes(played, poolMinGames, poolSize)
+	if len(heroes) == 0 {
+		// Private or empty match history.
+		return
+	}
+
+	now := time.Now()
+	pool = config.Pool{AccountID: accountID, Heroes: heroes, ImportedAt: &now}
+	if err := config.SavePool(pool); err != nil {
+		log.Printf("hero pool: %v", err)
+	}
+	st.SetHeroPool(heroes)
+	st.AppendOverlayLog(fmt.Sprintf("Hero pool: %d heroes imported", len(heroes)), 10)
+	engine.Refresh()
+}
+
+// watchSetup keeps the setup checklist in the overlay current: it checks
+// once the GSI server is up and again every setupRecheck while anything
+// still fails, so fixes show up without a restart.
+func watchSetup(st *state.GameState, opts gsi.CfgOptions) {
+	time.Sleep(2 * time.Second)
+	for {
+		checks := setup.Run(opts)
+		out := make([]state.SetupCheck, 0, len(checks))
+		for _, c := range checks {
+			out = append(out, state.SetupCheck{Name: c.Name, OK: c.OK, Detail: c.Detail, Fix: c.Fix})
+		}
+		st.SetSetupChecks(out)
+		if setup.Failed(checks) == 0 {
+			return
+		}
+		time.Sleep(setupRecheck)
+	}
+}
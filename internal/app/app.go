@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"image/color"
+	"strings"
 	"time"
 
 	"overlay/internal/state"
@@ -113,10 +114,21 @@ func (a *App) Draw(screen *ebiten.Image) {
 	for _, id := range snap.EnemyHeroesIDs {
 		statusMsg += fmt.Sprintf(" [%s]", snap.HeroIDToName[id])
 	}
+	statusMsg += "\nALLIES:"
+	for _, id := range snap.AllyHeroesIDs {
+		statusMsg += fmt.Sprintf(" [%s]", snap.HeroIDToName[id])
+	}
 
+	if setup := buildSetupChecklist(snap); setup != "" {
+		statusMsg += "\n\n" + setup
+	}
 	statusMsg += "\n\n" + buildGSIPanel(snap)
 	statusMsg += "\n\n" + buildCounterTable(snap, a.selectedHeroID(snap))
-	statusMsg += "\n\n" + buildBestPicksTable(snap)
+	if snap.DraftBanning {
+		statusMsg += "\n\n" + buildBanTable(snap)
+	} else {
+		statusMsg += "\n\n" + buildBestPicksTable(snap)
+	}
 
 	ebitenutil.DebugPrint(screen, statusMsg)
 }
@@ -159,7 +171,7 @@ func buildCounterTable(snap state.Snapshot, heroID int) string {
 	rows := make([]string, 0, 6)
 	rows = append(rows, fmt.Sprintf("Picked: %-20s |        ", name))
 
-	picks := snap.CounterPicksBy[heroID]
+	picks := availablePicks(snap, snap.CounterPicksBy[heroID])
 	for i := 0; i < 5; i++ {
 		if i < len(picks) {
 			pick := picks[i]
@@ -167,33 +179,148 @@ func buildCounterTable(snap state.Snapshot, heroID int) string {
 			if pickName == "" {
 				pickName = fmt.Sprintf("ID %d", pick.HeroID)
 			}
-			rows = append(rows, fmt.Sprintf("%-22s | %5.1f%%", pickName, pick.WinRate*100))
+			// DebugPrint's font is ASCII only, hence "+-" for the margin.
+			rows = append(rows, fmt.Sprintf("%-22s | %5.1f%% +-%4.1f", pickName, pick.WinRate*100, pick.Confidence*100))
 		} else {
 			rows = append(rows, fmt.Sprintf("%-22s | %5s", "-", "-"))
 		}
 	}
 
-	return formatTable("COUNTERS", rows)
+	return formatTable("COUNTERS"+sourceTag(snap), rows)
 }
 
 func buildBestPicksTable(snap state.Snapshot) string {
 	rows := make([]string, 0, 6)
 	rows = append(rows, fmt.Sprintf("%-22s | %5s", "Best Picks", "Score"))
 
+	best := availableScored(snap, snap.BestCounters)
 	for i := 0; i < 5; i++ {
-		if i < len(snap.BestCounters) {
-			pick := snap.BestCounters[i]
+		if i < len(best) {
+			pick := best[i]
 			pickName := snap.HeroIDToName[pick.HeroID]
 			if pickName == "" {
 				pickName = fmt.Sprintf("ID %d", pick.HeroID)
 			}
+			// "*" marks heroes from our hero pool.
+			if snap.InPool(pick.HeroID) {
+				pickName = "*" + pickName
+			}
 			rows = append(rows, fmt.Sprintf("%-22s | %5.1f%%", pickName, pick.Score*100))
 		} else {
 			rows = append(rows, fmt.Sprintf("%-22s | %5s", "-", "-"))
 		}
 	}
 
-	return formatTable("BEST PICKS", rows)
+	return formatTable("BEST PICKS"+sourceTag(snap), rows)
+}
+
+// buildSetupChecklist lists the setup checks while any of them fails, with
+// the fix for each failing one; once everything passes it disappears.
+func buildSetupChecklist(snap state.Snapshot) string {
+	failed := false
+	for _, c := range snap.SetupChecks {
+		if !c.OK {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return ""
+	}
+
+	rows := make([]string, 0, len(snap.SetupChecks))
+	for _, c := range snap.SetupChecks {
+		if c.OK {
+			rows = append(rows, "[x] "+c.Name)
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("[ ] %s: %s -> %s", c.Name, c.Detail, c.Fix))
+	}
+	return formatTable("SETUP", rows)
+}
+
+// buildBanTable takes the place of Best Picks while a ban turn is open.
+func buildBanTable(snap state.Snapshot) string {
+	rows := make([]string, 0, 6)
+	rows = append(rows, fmt.Sprintf("%-22s | %5s", "Ban ("+fallback(snap.DraftTurnTeam, "-")+" turn)", "Score"))
+
+	bans := availableScored(snap, snap.BanSuggestions)
+	for i := 0; i < 5; i++ {
+		if i < len(bans) {
+			ban := bans[i]
+			banName := snap.HeroIDToName[ban.HeroID]
+			if banName == "" {
+				banName = fmt.Sprintf("ID %d", ban.HeroID)
+			}
+			rows = append(rows, fmt.Sprintf("%-22s | %5.1f%%", banName, ban.Score*100))
+		} else {
+			rows = append(rows, fmt.Sprintf("%-22s | %5s", "-", "-"))
+		}
+	}
+
+	return formatTable("BAN SUGGESTIONS"+sourceTag(snap), rows)
+}
+
+// sourceTag names the matchup source behind the tables, so stale offline
+// numbers are not mistaken for live ones.
+func sourceTag(snap state.Snapshot) string {
+	if snap.MatchupSource == "" {
+		return ""
+	}
+	return " [" + snap.MatchupSource + "]"
+}
+
+func availablePicks(snap state.Snapshot, picks []state.CounterPick) []state.CounterPick {
+	out := make([]state.CounterPick, 0, len(picks))
+	for _, p := range picks {
+		if !snap.IsUnavailable(p.HeroID) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func availableScored(snap state.Snapshot, heroes []state.ScoredHero) []state.ScoredHero {
+	out := make([]state.ScoredHero, 0, len(heroes))
+	for _, h := range heroes {
+		if !snap.IsUnavailable(h.HeroID) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func buildDraftBoard(snap state.Snapshot) string {
+	type side struct {
+		picks []string
+		bans  []string
+	}
+	sides := map[string]*side{"radiant": {}, "dire": {}}
+	for _, e := range snap.Draft {
+		sd, ok := sides[e.Team]
+		if !ok {
+			continue
+		}
+		name := fallback(snap.HeroIDToName[e.HeroID], fmt.Sprintf("ID %d", e.HeroID))
+		if e.IsPick {
+			sd.picks = append(sd.picks, name)
+		} else {
+			sd.bans = append(sd.bans, name)
+		}
+	}
+
+	line := func(label, team string) string {
+		sd := sides[team]
+		if team == snap.GSITeam {
+			label += "*"
+		}
+		return fmt.Sprintf("%-3s %s | bans: %s",
+			label,
+			fallback(strings.Join(sd.picks, ", "), "-"),
+			fallback(strings.Join(sd.bans, ", "), "-"),
+		)
+	}
+	return fmt.Sprintf("DRAFT (%d)\n%s\n%s", len(snap.Draft), line("R", "radiant"), line("D", "dire"))
 }
 
 func formatTable(title string, rows []string) string {
@@ -217,16 +344,20 @@ func gsiLine(snap state.Snapshot) string {
 }
 
 func buildGSIPanel(snap state.Snapshot) string {
-	if snap.GSIHeroID == 0 || snap.GSIMapPhase == "picks" {
+	if inPickStage(snap) {
 		name := snap.GSIHeroName
 		if name == "" {
 			name = "Not picked"
 		}
-		return fmt.Sprintf("PICK STAGE\nHero: %s\nMatch: %s", name, fallback(snap.GSIMatchID, "-"))
+		panel := fmt.Sprintf("PICK STAGE\nHero: %s\nMatch: %s", name, fallback(snap.GSIMatchID, "-"))
+		if len(snap.Draft) > 0 {
+			panel += "\n" + buildDraftBoard(snap)
+		}
+		return panel
 	}
 
 	return fmt.Sprintf(
-		"IN-GAME\nHero: %s (Lv %d)\nK/D/A: %d/%d/%d  LH/D: %d/%d\nGPM/XPM: %d/%d  Gold: %d (%d+%d)\nHP/MP: %d/%d  %d/%d",
+		"IN-GAME\nHero: %s (Lv %d)\nK/D/A: %d/%d/%d  LH/D: %d/%d\nGPM/XPM: %d/%d  Gold: %d (%d+%d)\nHP/MP: %d/%d  %d/%d\nItems: %s",
 		fallback(snap.GSIHeroName, "Unknown"),
 		snap.GSIHeroLevel,
 		snap.GSIKills,
@@ -243,9 +374,33 @@ func buildGSIPanel(snap state.Snapshot) string {
 		snap.GSIHeroHPMax,
 		snap.GSIHeroMP,
 		snap.GSIHeroMPMax,
+		itemsLine(snap.GSIItems),
 	)
 }
 
+func itemsLine(items state.Items) string {
+	parts := make([]string, 0, len(items.Inventory))
+	for _, it := range items.Inventory {
+		if it.Name == "" {
+			continue
+		}
+		name := strings.TrimPrefix(it.Name, "item_")
+		if it.Cooldown > 0 {
+			name += fmt.Sprintf("(%d)", it.Cooldown)
+		}
+		parts = append(parts, name)
+	}
+	return fallback(strings.Join(parts, " "), "-")
+}
+
+func inPickStage(snap state.Snapshot) bool {
+	switch snap.GameState {
+	case state.GameStateHeroSelection, state.GameStateStrategyTime:
+		return true
+	}
+	return snap.GSIHeroID == 0 || snap.GSIMapPhase == "picks"
+}
+
 func fallback(val, def string) string {
 	if val == "" {
 		return def
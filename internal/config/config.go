@@ -0,0 +1,200 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultGSIAddr  = "127.0.0.1:3001"
+	DefaultGSIToken = "overlay123"
+	// DefaultMatchupsFile is the bundled offline dataset, looked up in the
+	// working directory and next to the executable.
+	DefaultMatchupsFile = "matchups.json"
+	DefaultScoring      = "bayes"
+	// DefaultDuosFile is the ally synergy dataset, looked up like
+	// DefaultMatchupsFile.
+	DefaultDuosFile = "duos.json"
+	// Best Picks weigh beating the enemy lineup double working with ours.
+	DefaultCounterWeight = 1.0
+	DefaultSynergyWeight = 0.5
+	// DefaultRoleBonus is added to heroes that fill a position our team is
+	// still missing; on the win-rate scale that is 3 points.
+	DefaultRoleBonus = 0.03
+	// DefaultPoolBonus is added to hero pool heroes in "boost" mode.
+	DefaultPoolBonus = 0.05
+	DefaultPoolMode  = "off"
+)
+
+const fileName = "config.json"
+
+type Config struct {
+	GSIAddr  string `json:"gsi_addr"`
+	GSIToken string `json:"gsi_token"`
+	// MatchupsPath points at the offline matchup dataset used when OpenDota
+	// cannot be reached.
+	MatchupsPath string `json:"matchups_path"`
+	// Scoring picks how counters are ranked: "raw", "wilson", "bayes" or
+	// "advantage".
+	Scoring string `json:"scoring"`
+	// DuosPath points at the ally synergy dataset.
+	DuosPath string  `json:"duos_path"`
+	Weights  Weights `json:"weights"`
+	// Position is the one we play: "1".."5" or carry, mid, offlane,
+	// support, hard support. Empty means any.
+	Position string `json:"position"`
+	// PoolMode applies the hero pool (pool.json) to Best Picks: "off",
+	// "restrict" to pool heroes only, or "boost" them by Weights.Pool.
+	PoolMode string `json:"pool_mode"`
+	// GSISections are the data blocks requested in the generated GSI cfg;
+	// empty means all the overlay uses.
+	GSISections []string `json:"gsi_sections"`
+}
+
+// Weights balances Best Picks between countering the enemies and synergy
+// with the allies; Role and Pool are bonuses for filling a missing position
+// and for pool heroes. A weight left out of config.json keeps its default
+// and an explicit 0 turns that part off; negative weights are replaced by
+// the default, as are Counter and Synergy when both are 0.
+type Weights struct {
+	Counter float64 `json:"counter"`
+	Synergy float64 `json:"synergy"`
+	Role    float64 `json:"role"`
+	Pool    float64 `json:"pool"`
+}
+
+func Default() Config {
+	return Config{
+		GSIAddr:      DefaultGSIAddr,
+		GSIToken:     DefaultGSIToken,
+		MatchupsPath: DefaultMatchupsFile,
+		Scoring:      DefaultScoring,
+		DuosPath:     DefaultDuosFile,
+		Weights: Weights{
+			Counter: DefaultCounterWeight,
+			Synergy: DefaultSynergyWeight,
+			Role:    DefaultRoleBonus,
+			Pool:    DefaultPoolBonus,
+		},
+		PoolMode: DefaultPoolMode,
+	}
+}
+
+// Dir is the per-user directory for the config file and anything else the
+// overlay keeps between runs.
+func Dir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "dota-overlay"), nil
+}
+
+// CacheDir holds cached OpenDota responses.
+func CacheDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cache"), nil
+}
+
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// Load reads config.json from Dir. A missing file is not an error, and any
+// field left empty keeps its default. OVERLAY_GSI_TOKEN overrides the token and OVERLAY_MATCHUPS the
+// matchups file.
+func Load() (Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err == nil {
+		err = readFile(path, &cfg)
+	}
+
+	if token := os.Getenv("OVERLAY_GSI_TOKEN"); token != "" {
+		cfg.GSIToken = token
+	}
+	if matchups := os.Getenv("OVERLAY_MATCHUPS"); matchups != "" {
+		cfg.MatchupsPath = matchups
+	}
+	cfg.fillDefaults()
+	return cfg, err
+}
+
+func readFile(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, cfg)
+}
+
+func (c *Config) fillDefaults() {
+	def := Default()
+	if c.GSIAddr == "" {
+		c.GSIAddr = def.GSIAddr
+	}
+	if c.GSIToken == "" {
+		c.GSIToken = def.GSIToken
+	}
+	if c.MatchupsPath == "" {
+		c.MatchupsPath = def.MatchupsPath
+	}
+	if c.Scoring == "" {
+		c.Scoring = def.Scoring
+	}
+	if c.DuosPath == "" {
+		c.DuosPath = def.DuosPath
+	}
+	if c.PoolMode == "" {
+		c.PoolMode = def.PoolMode
+	}
+	c.Weights.fillDefaults(def.Weights)
+}
+
+func (w *Weights) fillDefaults(def Weights) {
+	for _, f := range []struct{ v, def *float64 }{
+		{&w.Counter, &def.Counter},
+		{&w.Synergy, &def.Synergy},
+		{&w.Role, &def.Role},
+		{&w.Pool, &def.Pool},
+	} {
+		if *f.v < 0 {
+			*f.v = *f.def
+		}
+	}
+	// Best Picks need something to rank by.
+	if w.Counter == 0 && w.Synergy == 0 {
+		w.Counter, w.Synergy = def.Counter, def.Synergy
+	}
+}
+
+// ResolveDataPath finds a data file as given, or, for a relative path, next
+// to the executable. The first existing candidate wins.
+func ResolveDataPath(path string) (string, error) {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) {
+		if exe, err := os.Executable(); err == nil {
+			candidates = append(candidates, filepath.Join(filepath.Dir(exe), path))
+		}
+	}
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("config: %s not found", path)
+}
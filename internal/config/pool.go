@@ -0,0 +1,73 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const poolFileName = "pool.json"
+
+// Pool is the list of heroes we actually play, kept in pool.json next to
+// config.json. ImportedAt is set when the list came from OpenDota; a pool
+// without it was written by hand and is never replaced by an import.
+type Pool struct {
+	AccountID  string     `json:"account_id,omitempty"`
+	Heroes     []int      `json:"heroes"`
+	ImportedAt *time.Time `json:"imported_at,omitempty"`
+}
+
+func PoolPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, poolFileName), nil
+}
+
+// LoadPool reads pool.json; a missing file is an empty pool.
+func LoadPool() (Pool, error) {
+	var pool Pool
+	path, err := PoolPath()
+	if err != nil {
+		return pool, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return pool, nil
+	}
+	if err != nil {
+		return pool, err
+	}
+	return pool, json.Unmarshal(raw, &pool)
+}
+
+func SavePool(pool Pool) error {
+	path, err := PoolPath()
+	if err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(pool, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// NeedsImport reports whether the pool should be (re)imported for
+// accountID: it is empty, or it was imported for someone else or more than
+// maxAge ago.
+func (p Pool) NeedsImport(accountID string, maxAge time.Duration) bool {
+	if len(p.Heroes) == 0 {
+		return true
+	}
+	if p.ImportedAt == nil {
+		return false
+	}
+	return p.AccountID != accountID || time.Since(*p.ImportedAt) > maxAge
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPoolNeedsImport(t *testing.T) {
+	const maxAge = 7 * 24 * time.Hour
+	fresh := time.Now().Add(-time.Hour)
+	stale := time.Now().Add(-maxAge - time.Hour)
+
+	tests := []struct {
+		name string
+		pool Pool
+		want bool
+	}{
+		{"empty", Pool{}, true},
+		{"hand-written", Pool{Heroes: []int{1, 2}}, false},
+		{"hand-written for someone else", Pool{AccountID: "2", Heroes: []int{1}}, false},
+		{"imported recently", Pool{AccountID: "1", Heroes: []int{1}, ImportedAt: &fresh}, false},
+		{"imported for someone else", Pool{AccountID: "2", Heroes: []int{1}, ImportedAt: &fresh}, true},
+		{"imported too long ago", Pool{AccountID: "1", Heroes: []int{1}, ImportedAt: &stale}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pool.NeedsImport("1", maxAge); got != tt.want {
+				t.Errorf("NeedsImport = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,10 +1,9 @@
 package dotaplus
 
 import (
-	"encoding/json"
 	"fmt"
 	"image/color"
-	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,40 +17,82 @@ const (
 	ViewHeight = 220
 )
 
-const fetchInterval = 500 * time.Millisecond
-
 type Snapshot struct {
-	Status       string    `json:"status"`
-	GSIStatus    string    `json:"gsi_status"`
-	GSILastAt    time.Time `json:"gsi_last_at"`
-	GSIMatchID   string    `json:"gsi_match_id"`
-	GSIMapPhase  string    `json:"gsi_map_phase"`
-	GSIMapName   string    `json:"gsi_map_name"`
-	GSIHeroID    int       `json:"gsi_hero_id"`
-	GSIHeroName  string    `json:"gsi_hero_name"`
-	GSIHeroLevel int       `json:"gsi_hero_level"`
-	GSIHeroHP    int       `json:"gsi_hero_hp"`
-	GSIHeroHPMax int       `json:"gsi_hero_hp_max"`
-	GSIHeroMP    int       `json:"gsi_hero_mp"`
-	GSIHeroMPMax int       `json:"gsi_hero_mp_max"`
-	GSIKills     int       `json:"gsi_kills"`
-	GSIDeaths    int       `json:"gsi_deaths"`
-	GSIAssists   int       `json:"gsi_assists"`
-	GSILastHits  int       `json:"gsi_last_hits"`
-	GSIDenies    int       `json:"gsi_denies"`
-	GSIGold      int       `json:"gsi_gold"`
-	GSIGoldR     int       `json:"gsi_gold_r"`
-	GSIGoldU     int       `json:"gsi_gold_u"`
-	GSIGPM       int       `json:"gsi_gpm"`
-	GSIXPM       int       `json:"gsi_xpm"`
+	Status       string     `json:"status"`
+	GSIStatus    string     `json:"gsi_status"`
+	GSILastAt    time.Time  `json:"gsi_last_at"`
+	GSIMatchID   string     `json:"gsi_match_id"`
+	GSIMapPhase  string     `json:"gsi_map_phase"`
+	GSIMapName   string     `json:"gsi_map_name"`
+	GameState    string     `json:"game_state"`
+	GSIHeroID    int        `json:"gsi_hero_id"`
+	GSIHeroName  string     `json:"gsi_hero_name"`
+	GSIHeroLevel int        `json:"gsi_hero_level"`
+	GSIHeroHP    int        `json:"gsi_hero_hp"`
+	GSIHeroHPMax int        `json:"gsi_hero_hp_max"`
+	GSIHeroMP    int        `json:"gsi_hero_mp"`
+	GSIHeroMPMax int        `json:"gsi_hero_mp_max"`
+	GSIKills     int        `json:"gsi_kills"`
+	GSIDeaths    int        `json:"gsi_deaths"`
+	GSIAssists   int        `json:"gsi_assists"`
+	GSILastHits  int        `json:"gsi_last_hits"`
+	GSIDenies    int        `json:"gsi_denies"`
+	GSIGold      int        `json:"gsi_gold"`
+	GSIGoldR     int        `json:"gsi_gold_r"`
+	GSIGoldU     int        `json:"gsi_gold_u"`
+	GSIGPM       int        `json:"gsi_gpm"`
+	GSIXPM       int        `json:"gsi_xpm"`
+	GSIItems     Items      `json:"gsi_items"`
+	GSIHero      HeroStatus `json:"gsi_hero_status"`
+	GSIAbilities []Ability  `json:"gsi_abilities"`
+}
+
+type Ability struct {
+	Name        string `json:"name"`
+	Level       int    `json:"level"`
+	Cooldown    int    `json:"cooldown"`
+	MaxCooldown int    `json:"max_cooldown"`
+	Ultimate    bool   `json:"ultimate"`
+}
+
+type HeroStatus struct {
+	Alive           bool   `json:"alive"`
+	RespawnSeconds  int    `json:"respawn_seconds"`
+	BuybackCost     int    `json:"buyback_cost"`
+	BuybackCooldown int    `json:"buyback_cooldown"`
+	Stunned         bool   `json:"stunned"`
+	Silenced        bool   `json:"silenced"`
+	Hexed           bool   `json:"hexed"`
+	Disarmed        bool   `json:"disarmed"`
+	Muted           bool   `json:"muted"`
+	Break           bool   `json:"break"`
+	MagicImmune     bool   `json:"magic_immune"`
+	Smoked          bool   `json:"smoked"`
+	AghanimsScepter bool   `json:"aghanims_scepter"`
+	AghanimsShard   bool   `json:"aghanims_shard"`
+	Talents         []bool `json:"talents"`
+}
+
+type Item struct {
+	Name        string `json:"name"`
+	Charges     int    `json:"charges"`
+	Cooldown    int    `json:"cooldown"`
+	MaxCooldown int    `json:"max_cooldown"`
+	CanCast     bool   `json:"can_cast"`
+	Passive     bool   `json:"passive"`
+}
+
+type Items struct {
+	Inventory []Item `json:"inventory"`
+	Stash     []Item `json:"stash"`
+	Teleport  Item   `json:"teleport"`
+	Neutral   []Item `json:"neutral"`
 }
 
 type App struct {
 	mu          sync.RWMutex
 	snap        Snapshot
-	lastFetch   time.Time
 	lastUpdated time.Time
-	fetching    bool
 	fetchErr    string
 
 	dragging     bool
@@ -67,23 +108,15 @@ type App struct {
 	windowStartH int
 }
 
-func New() *App {
-	return &App{}
+// New starts streaming snapshots from the overlay's GSI server at addr.
+func New(addr string) *App {
+	a := &App{}
+	go a.stream("http://" + addr + "/stream")
+	return a
 }
 
 func (a *App) Update() error {
 	a.handleDragResize()
-
-	if time.Since(a.lastFetch) < fetchInterval {
-		return nil
-	}
-	if a.fetching {
-		return nil
-	}
-	a.fetching = true
-	a.lastFetch = time.Now()
-
-	go a.fetchSnapshot()
 	return nil
 }
 
@@ -137,34 +170,6 @@ func (a *App) handleDragResize() {
 	}
 }
 
-func (a *App) fetchSnapshot() {
-	defer func() { a.fetching = false }()
-
-	resp, err := http.Get("http://127.0.0.1:3001/snapshot")
-	if err != nil {
-		a.setFetchError(err)
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		a.setFetchError(fmt.Errorf("status %d", resp.StatusCode))
-		return
-	}
-
-	var snap Snapshot
-	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
-		a.setFetchError(err)
-		return
-	}
-
-	a.mu.Lock()
-	a.snap = snap
-	a.fetchErr = ""
-	a.lastUpdated = time.Now()
-	a.mu.Unlock()
-}
-
 func (a *App) setFetchError(err error) {
 	a.mu.Lock()
 	a.fetchErr = err.Error()
@@ -236,6 +241,7 @@ func buildPanelLines(snap Snapshot) []string {
 		lines = append(lines, "Match: "+fallback(snap.GSIMatchID, "-"))
 		lines = append(lines, "Map: "+fallback(snap.GSIMapName, "-"))
 		lines = append(lines, "Phase: "+fallback(snap.GSIMapPhase, "-"))
+		lines = append(lines, "State: "+fallback(strings.TrimPrefix(snap.GameState, "DOTA_GAMERULES_STATE_"), "-"))
 		return lines
 	}
 
@@ -247,12 +253,139 @@ func buildPanelLines(snap Snapshot) []string {
 	lines = append(lines, fmt.Sprintf("GPM/XPM: %d/%d", snap.GSIGPM, snap.GSIXPM))
 	lines = append(lines, fmt.Sprintf("Gold: %d (%d+%d)", snap.GSIGold, snap.GSIGoldR, snap.GSIGoldU))
 	lines = append(lines, fmt.Sprintf("HP/MP: %d/%d  %d/%d", snap.GSIHeroHP, snap.GSIHeroHPMax, snap.GSIHeroMP, snap.GSIHeroMPMax))
+	lines = append(lines, "Items: "+fallback(itemList(inventorySlots(snap.GSIItems.Inventory)), "-"))
+	lines = append(lines, "Backpack: "+fallback(itemList(backpackSlots(snap.GSIItems.Inventory)), "-"))
+	lines = append(lines, "Stash: "+fallback(itemList(snap.GSIItems.Stash), "-"))
+	lines = append(lines, "Neutral: "+fallback(itemList(snap.GSIItems.Neutral), "-"))
+	lines = append(lines, "TP: "+itemLabel(snap.GSIItems.Teleport))
+	lines = append(lines, "Ult: "+ultimateLine(snap.GSIAbilities))
+	lines = append(lines, "Buyback: "+buybackLine(snap))
+	lines = append(lines, "Status: "+statusFlagsLine(snap.GSIHero))
 	lines = append(lines, "Match: "+fallback(snap.GSIMatchID, "-"))
 	lines = append(lines, "Map: "+fallback(snap.GSIMapName, "-"))
 	lines = append(lines, "Phase: "+fallback(snap.GSIMapPhase, "-"))
+	lines = append(lines, "State: "+fallback(strings.TrimPrefix(snap.GameState, "DOTA_GAMERULES_STATE_"), "-"))
 	return lines
 }
 
+const backpackStart = 6
+
+func ultimateLine(abilities []Ability) string {
+	for _, ab := range abilities {
+		if !ab.Ultimate {
+			continue
+		}
+		switch {
+		case ab.Level == 0:
+			return "not learned"
+		case ab.Cooldown > 0:
+			return fmt.Sprintf("%ds / %ds", ab.Cooldown, ab.MaxCooldown)
+		default:
+			return "ready"
+		}
+	}
+	return "-"
+}
+
+func buybackLine(snap Snapshot) string {
+	hero := snap.GSIHero
+	if hero.BuybackCost == 0 {
+		return "-"
+	}
+	line := fmt.Sprintf("%d gold", hero.BuybackCost)
+	switch {
+	case hero.BuybackCooldown > 0:
+		line += fmt.Sprintf(", cooldown %ds", hero.BuybackCooldown)
+	case snap.GSIGold < hero.BuybackCost:
+		line += fmt.Sprintf(", need %d", hero.BuybackCost-snap.GSIGold)
+	default:
+		line += ", ready"
+	}
+	if !hero.Alive && hero.RespawnSeconds > 0 {
+		line += fmt.Sprintf(" | respawn %ds", hero.RespawnSeconds)
+	}
+	return line
+}
+
+func statusFlagsLine(hero HeroStatus) string {
+	flags := make([]string, 0, 8)
+	for _, f := range []struct {
+		on   bool
+		name string
+	}{
+		{hero.Stunned, "stunned"},
+		{hero.Hexed, "hexed"},
+		{hero.Silenced, "silenced"},
+		{hero.Disarmed, "disarmed"},
+		{hero.Muted, "muted"},
+		{hero.Break, "break"},
+		{hero.MagicImmune, "magic immune"},
+		{hero.Smoked, "smoked"},
+	} {
+		if f.on {
+			flags = append(flags, f.name)
+		}
+	}
+
+	talents := 0
+	for _, t := range hero.Talents {
+		if t {
+			talents++
+		}
+	}
+	extra := fmt.Sprintf("talents %d/%d", talents, len(hero.Talents))
+	if hero.AghanimsScepter {
+		extra += " scepter"
+	}
+	if hero.AghanimsShard {
+		extra += " shard"
+	}
+
+	if len(flags) == 0 {
+		return "ok | " + extra
+	}
+	return strings.Join(flags, ", ") + " | " + extra
+}
+
+func inventorySlots(items []Item) []Item {
+	if len(items) > backpackStart {
+		return items[:backpackStart]
+	}
+	return items
+}
+
+func backpackSlots(items []Item) []Item {
+	if len(items) > backpackStart {
+		return items[backpackStart:]
+	}
+	return nil
+}
+
+func itemList(items []Item) string {
+	parts := make([]string, 0, len(items))
+	for _, it := range items {
+		if it.Name == "" {
+			continue
+		}
+		parts = append(parts, itemLabel(it))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func itemLabel(it Item) string {
+	if it.Name == "" {
+		return "-"
+	}
+	label := strings.TrimPrefix(it.Name, "item_")
+	if it.Charges > 1 {
+		label += fmt.Sprintf(" x%d", it.Charges)
+	}
+	if it.Cooldown > 0 {
+		label += fmt.Sprintf(" (%ds)", it.Cooldown)
+	}
+	return label
+}
+
 func fallback(val, def string) string {
 	if val == "" {
 		return def
@@ -0,0 +1,88 @@
+package dotaplus
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	reconnectMin = 500 * time.Millisecond
+	reconnectMax = 5 * time.Second
+)
+
+// stream follows the overlay's Server-Sent Events endpoint for as long as the
+// app runs, reconnecting with a growing delay while the overlay is down.
+func (a *App) stream(url string) {
+	client := &http.Client{}
+	delay := reconnectMin
+	for {
+		received, err := a.readStream(client, url)
+		if err != nil {
+			a.setFetchError(err)
+		}
+		if received {
+			delay = reconnectMin
+		}
+		time.Sleep(delay)
+		delay *= 2
+		if delay > reconnectMax {
+			delay = reconnectMax
+		}
+	}
+}
+
+// readStream reads one connection until it drops and reports whether any
+// snapshot arrived on it.
+func (a *App) readStream(client *http.Client, url string) (bool, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	received := false
+	reader := bufio.NewReader(resp.Body)
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return received, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var snap Snapshot
+			if err := json.Unmarshal([]byte(data.String()), &snap); err != nil {
+				return received, err
+			}
+			data.Reset()
+			a.setSnapshot(snap)
+			received = true
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+}
+
+func (a *App) setSnapshot(snap Snapshot) {
+	a.mu.Lock()
+	a.snap = snap
+	a.fetchErr = ""
+	a.lastUpdated = time.Now()
+	a.mu.Unlock()
+}
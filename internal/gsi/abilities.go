@@ -0,0 +1,26 @@
+package gsi
+
+import "encoding/json"
+
+type Ability struct {
+	Name        string `json:"name"`
+	Level       int    `json:"level"`
+	CanCast     bool   `json:"can_cast"`
+	Passive     bool   `json:"passive"`
+	Active      bool   `json:"ability_active"`
+	Cooldown    int    `json:"cooldown"`
+	MaxCooldown int    `json:"max_cooldown"`
+	Ultimate    bool   `json:"ultimate"`
+}
+
+// Abilities is the "abilities" block ordered by slot (ability0, ability1, ...).
+type Abilities []Ability
+
+func (a *Abilities) UnmarshalJSON(data []byte) error {
+	var raw map[string]Ability
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*a = collectSlots(raw, "ability")
+	return nil
+}
@@ -0,0 +1,141 @@
+package gsi
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"overlay/internal/vdf"
+)
+
+// CfgFileName is the file Dota reads our GSI settings from; Dota picks up
+// every gamestate_integration_*.cfg in CfgDir at launch.
+const CfgFileName = "gamestate_integration_overlay.cfg"
+
+const cfgServiceName = "Dota Overlay"
+
+// DefaultSections are the data blocks the overlay uses.
+var DefaultSections = []string{"provider", "map", "player", "hero", "abilities", "items", "draft"}
+
+// CfgOptions is what goes into the cfg; it should match the running server.
+type CfgOptions struct {
+	Addr     string
+	Token    string
+	Sections []string
+}
+
+// NewCfgOptions lower-cases and trims sections, as Dota's data keys are
+// matched without case, and drops blanks and duplicates. It fills in
+// DefaultSections when none are left.
+func NewCfgOptions(addr, token string, sections []string) CfgOptions {
+	var clean []string
+	for _, s := range sections {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s != "" && !slices.Contains(clean, s) {
+			clean = append(clean, s)
+		}
+	}
+	if len(clean) == 0 {
+		clean = DefaultSections
+	}
+	return CfgOptions{Addr: addr, Token: token, Sections: clean}
+}
+
+func (o CfgOptions) uri() string {
+	return ServerURL(o.Addr)
+}
+
+// cfgQuote quotes s for a KeyValues file, which only knows the \\ and \"
+// escapes; Go's %q would write \u and \x escapes Dota reads literally.
+func cfgQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// CfgDir is where Dota looks for GSI configs under an install folder such
+// as ".../steamapps/common/dota 2 beta".
+func CfgDir(dotaDir string) string {
+	return filepath.Join(dotaDir, "game", "dota", "cfg", "gamestate_integration")
+}
+
+// RenderCfg writes the cfg in the same layout as the one shipped in gsi/.
+func RenderCfg(opts CfgOptions) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s\n{\n", cfgQuote(cfgServiceName))
+	fmt.Fprintf(&b, "  %-15s %s\n", `"uri"`, cfgQuote(opts.uri()))
+	fmt.Fprintf(&b, "  %-15s %s\n", `"timeout"`, `"5.0"`)
+	fmt.Fprintf(&b, "  %-15s %s\n", `"buffer"`, `"0.1"`)
+	fmt.Fprintf(&b, "  %-15s %s\n", `"throttle"`, `"0.1"`)
+	fmt.Fprintf(&b, "  %-15s %s\n", `"heartbeat"`, `"10.0"`)
+	b.WriteString("  \"data\"\n  {\n")
+	for _, s := range opts.Sections {
+		fmt.Fprintf(&b, "    %-13s %s\n", cfgQuote(s), `"1"`)
+	}
+	b.WriteString("  }\n  \"auth\"\n  {\n")
+	fmt.Fprintf(&b, "    \"token\" %s\n", cfgQuote(opts.Token))
+	b.WriteString("  }\n}\n")
+	return b.Bytes()
+}
+
+// CheckCfg compares the cfg at path with opts and lists what differs: URI,
+// token or enabled data sections. An empty list means the file is current.
+// A missing file returns an error satisfying errors.Is(err, os.ErrNotExist).
+func CheckCfg(path string, opts CfgOptions) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	doc, err := vdf.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.Pairs) == 0 || doc.Pairs[0].Object == nil {
+		return []string{"no settings block"}, nil
+	}
+	root := doc.Pairs[0].Object
+
+	var diffs []string
+	if uri, _ := root.Get("uri"); uri != opts.uri() {
+		diffs = append(diffs, fmt.Sprintf("uri is %q, want %q", uri, opts.uri()))
+	}
+	if token, _ := root.Child("auth").Get("token"); token != opts.Token {
+		diffs = append(diffs, "auth token differs")
+	}
+
+	var enabled []string
+	if data := root.Child("data"); data != nil {
+		for _, p := range data.Pairs {
+			if p.Object == nil && p.Value == "1" {
+				enabled = append(enabled, strings.ToLower(p.Key))
+			}
+		}
+	}
+	for _, s := range opts.Sections {
+		if !slices.Contains(enabled, strings.ToLower(s)) {
+			diffs = append(diffs, "data section "+s+" is off")
+		}
+	}
+	return diffs, nil
+}
+
+// InstallCfg writes the cfg into dir unless an equivalent one is already
+// there, and reports whether it wrote.
+func InstallCfg(dir string, opts CfgOptions) (path string, written bool, err error) {
+	path = filepath.Join(dir, CfgFileName)
+	if diffs, err := CheckCfg(path, opts); err == nil && len(diffs) == 0 {
+		return path, false, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return path, false, err
+	}
+	if err := os.WriteFile(path, RenderCfg(opts), 0o644); err != nil {
+		return path, false, err
+	}
+	return path, true, nil
+}
@@ -0,0 +1,32 @@
+package gsi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewCfgOptionsSections(t *testing.T) {
+	opts := NewCfgOptions(":3001", "t", []string{"Hero", " map ", "hero", ""})
+	if want := []string{"hero", "map"}; !slices.Equal(opts.Sections, want) {
+		t.Errorf("Sections = %q, want %q", opts.Sections, want)
+	}
+	if opts := NewCfgOptions(":3001", "t", []string{" "}); !slices.Equal(opts.Sections, DefaultSections) {
+		t.Errorf("Sections = %q without any, want DefaultSections", opts.Sections)
+	}
+}
+
+func TestInstallCfgIsStable(t *testing.T) {
+	dir := t.TempDir()
+	opts := NewCfgOptions(":3001", `to"k\en`, []string{"Hero", "Map"})
+
+	if _, written, err := InstallCfg(dir, opts); err != nil || !written {
+		t.Fatalf("first InstallCfg: written = %v, err = %v", written, err)
+	}
+	path, written, err := InstallCfg(dir, opts)
+	if err != nil || written {
+		t.Fatalf("second InstallCfg: written = %v, err = %v, want an unchanged file", written, err)
+	}
+	if diffs, err := CheckCfg(path, opts); err != nil || len(diffs) > 0 {
+		t.Fatalf("CheckCfg = %q, %v, want no differences", diffs, err)
+	}
+}
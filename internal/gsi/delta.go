@@ -0,0 +1,50 @@
+package gsi
+
+import "encoding/json"
+
+// Delta is a "previously" or "added" block. Every key is either a nested
+// object with the changed fields or a bare true when the whole section
+// appeared or disappeared since the last tick.
+type Delta map[string]json.RawMessage
+
+// Section returns the nested delta for key, or nil when the key is missing or
+// is not an object.
+func (d Delta) Section(key string) Delta {
+	raw, ok := d[key]
+	if !ok {
+		return nil
+	}
+	var sub Delta
+	if err := json.Unmarshal(raw, &sub); err != nil {
+		return nil
+	}
+	return sub
+}
+
+func (d Delta) Has(key string) bool {
+	_, ok := d[key]
+	return ok
+}
+
+func (d Delta) Int(key string) (int, bool) {
+	var v int
+	return v, d.decode(key, &v)
+}
+
+func (d Delta) Bool(key string) (bool, bool) {
+	var v bool
+	return v, d.decode(key, &v)
+}
+
+func (d Delta) String(key string) (string, bool) {
+	var v string
+	return v, d.decode(key, &v)
+}
+
+func (d Delta) decode(key string, v any) bool {
+	raw, ok := d[key]
+	if !ok {
+		return false
+	}
+	return json.Unmarshal(raw, v) == nil
+}
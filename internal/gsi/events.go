@@ -0,0 +1,223 @@
+package gsi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type EventKind string
+
+const (
+	EventHeroSelected    EventKind = "hero_selected"
+	EventHeroDied        EventKind = "hero_died"
+	EventHeroRespawned   EventKind = "hero_respawned"
+	EventLevelUp         EventKind = "level_up"
+	EventItemPurchased   EventKind = "item_purchased"
+	EventItemSold        EventKind = "item_sold"
+	EventAbilityLevelled EventKind = "ability_levelled"
+	EventKill            EventKind = "kill"
+	EventGoldSpent       EventKind = "gold_spent"
+	EventPhaseChanged    EventKind = "phase_changed"
+
+	// Emitted by the server when it first places a hero on a team; Value is
+	// the hero ID.
+	EventAllyPicked  EventKind = "ally_picked"
+	EventEnemyPicked EventKind = "enemy_picked"
+
+	// Emitted by the server when the payload belongs to a new match and the
+	// per-match state was reset; Name is the new match ID.
+	EventMatchStarted EventKind = "match_started"
+
+	// Emitted by the server when GSI reports a different local player; Name
+	// is the account ID.
+	EventPlayerChanged EventKind = "player_changed"
+
+	// Emitted by the server when a ban turn starts or the bans close; Name is
+	// "ban" or "pick".
+	EventDraftTurn EventKind = "draft_turn"
+)
+
+// Event is a single change derived from a GSI tick.
+//
+// Name is the item, ability or game state involved, Previous is the game
+// state we left on EventPhaseChanged, and Value carries the number that
+// matters for the kind: hero ID, new level, kill count or gold spent.
+// EventItemSold also covers items consumed or combined into a recipe, as GSI
+// cannot tell these apart.
+type Event struct {
+	Kind     EventKind
+	Name     string
+	Previous string
+	Value    int
+	GameTime int
+}
+
+func (e Event) String() string {
+	switch e.Kind {
+	case EventHeroSelected:
+		return fmt.Sprintf("hero selected: %d", e.Value)
+	case EventAllyPicked:
+		return fmt.Sprintf("ally: %d", e.Value)
+	case EventEnemyPicked:
+		return fmt.Sprintf("enemy: %d", e.Value)
+	case EventMatchStarted:
+		return "new match " + e.Name
+	case EventPlayerChanged:
+		return "player " + e.Name
+	case EventDraftTurn:
+		return "draft: " + e.Name
+	case EventHeroDied, EventHeroRespawned:
+		return strings.ReplaceAll(string(e.Kind), "_", " ")
+	case EventLevelUp:
+		return fmt.Sprintf("level %d", e.Value)
+	case EventItemPurchased:
+		return "bought " + strings.TrimPrefix(e.Name, "item_")
+	case EventItemSold:
+		return "lost " + strings.TrimPrefix(e.Name, "item_")
+	case EventAbilityLevelled:
+		return fmt.Sprintf("%s -> %d", e.Name, e.Value)
+	case EventKill:
+		return fmt.Sprintf("kill #%d", e.Value)
+	case EventGoldSpent:
+		return fmt.Sprintf("spent %d gold", e.Value)
+	case EventPhaseChanged:
+		return fmt.Sprintf("%s -> %s", e.Previous, e.Name)
+	}
+	return string(e.Kind)
+}
+
+// Events turns the "previously" and "added" blocks of curr into typed
+// events. "added" marks keys that did not exist on the last tick: the hero
+// block after a reconnect, abilities granted mid-game and newly filled item
+// slots. prev is the last payload we saw and is only used for hero
+// selection, which GSI does not report as a delta when the hero changes.
+func Events(prev, curr *Payload) []Event {
+	var events []Event
+	emit := func(ev Event) {
+		ev.GameTime = curr.Map.GameTime
+		events = append(events, ev)
+	}
+
+	was, added := curr.Previously, curr.Added
+	if curr.Hero.ID > 0 {
+		if prev != nil && curr.Hero.ID != prev.Hero.ID || prev == nil && added.Has("hero") {
+			emit(Event{Kind: EventHeroSelected, Value: curr.Hero.ID})
+		}
+	}
+
+	if len(was) == 0 && len(added) == 0 {
+		return events
+	}
+
+	if m := was.Section("map"); m != nil {
+		if old, ok := m.String("game_state"); ok && old != curr.Map.GameState {
+			emit(Event{Kind: EventPhaseChanged, Name: curr.Map.GameState, Previous: old})
+		}
+	}
+
+	died := false
+	if h := was.Section("hero"); h != nil {
+		if alive, ok := h.Bool("alive"); ok && alive != curr.Hero.Alive {
+			if curr.Hero.Alive {
+				emit(Event{Kind: EventHeroRespawned})
+			} else {
+				died = true
+				emit(Event{Kind: EventHeroDied})
+			}
+		}
+		if lvl, ok := h.Int("level"); ok && curr.Hero.Level > lvl {
+			emit(Event{Kind: EventLevelUp, Value: curr.Hero.Level})
+		}
+	}
+
+	if pl := was.Section("player"); pl != nil {
+		if kills, ok := pl.Int("kills"); ok && curr.Player.Kills > kills {
+			emit(Event{Kind: EventKill, Value: curr.Player.Kills})
+		}
+		// Dying costs unreliable gold, which is not spending.
+		if gold, ok := pl.Int("gold"); ok && gold > curr.Player.Gold && !died {
+			emit(Event{Kind: EventGoldSpent, Value: gold - curr.Player.Gold})
+		}
+	}
+
+	ab, newAb := was.Section("abilities"), added.Section("abilities")
+	if ab != nil || newAb != nil {
+		for i, a := range curr.Abilities {
+			key := fmt.Sprintf("ability%d", i)
+			if slot := ab.Section(key); slot != nil {
+				if lvl, ok := slot.Int("level"); ok && a.Level > lvl {
+					emit(Event{Kind: EventAbilityLevelled, Name: a.Name, Value: a.Level})
+				}
+			} else if newAb.Has(key) && a.Level > 0 {
+				// A granted ability (e.g. from Aghanim's) shows up levelled.
+				emit(Event{Kind: EventAbilityLevelled, Name: a.Name, Value: a.Level})
+			}
+		}
+	}
+
+	it, newIt := was.Section("items"), added.Section("items")
+	if it != nil || newIt != nil {
+		for _, ev := range itemEvents(curr.Items, it, newIt) {
+			emit(ev)
+		}
+	}
+
+	return events
+}
+
+// itemEvents compares how many copies of each item we own across inventory
+// and stash before and after the tick. Counting instead of comparing slots
+// keeps moving an item between slots from looking like a trade. Slots in
+// added did not exist before the tick, so they held nothing.
+func itemEvents(curr Items, was, added Delta) []Event {
+	before := make(map[string]int)
+	after := make(map[string]int)
+
+	for key, item := range curr.slots {
+		if !ownedSlot(key) {
+			continue
+		}
+		if !item.Empty() {
+			after[item.Name]++
+		}
+
+		name := item.Name
+		if added.Has(key) {
+			name = ""
+		} else if slot := was.Section(key); slot != nil {
+			if old, ok := slot.String("name"); ok {
+				name = old
+			}
+		}
+		if name != "" && name != emptyItemName {
+			before[name]++
+		}
+	}
+
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var events []Event
+	for _, name := range names {
+		for n := before[name]; n < after[name]; n++ {
+			events = append(events, Event{Kind: EventItemPurchased, Name: name})
+		}
+		for n := after[name]; n < before[name]; n++ {
+			events = append(events, Event{Kind: EventItemSold, Name: name})
+		}
+	}
+	return events
+}
+
+func ownedSlot(key string) bool {
+	return strings.HasPrefix(key, "slot") || strings.HasPrefix(key, "stash")
+}
@@ -0,0 +1,175 @@
+package gsi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+// The payloads are trimmed ticks in the shape gsi_log.txt records them.
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		prev string // "" means no earlier payload
+		curr string
+		want []Event
+	}{
+		{
+			name: "death",
+			prev: `{"hero": {"id": 13, "alive": true}}`,
+			curr: `{
+				"map": {"game_time": 610},
+				"player": {"gold": 1410},
+				"hero": {"id": 13, "level": 9, "alive": false, "respawn_seconds": 24},
+				"previously": {
+					"player": {"deaths": 0, "gold": 1620, "gold_unreliable": 1575},
+					"hero": {"alive": true, "respawn_seconds": 0, "health": 87}
+				}
+			}`,
+			want: []Event{{Kind: EventHeroDied, GameTime: 610}},
+		},
+		{
+			name: "respawn",
+			prev: `{"hero": {"id": 13, "alive": false}}`,
+			curr: `{
+				"map": {"game_time": 634},
+				"hero": {"id": 13, "level": 9, "alive": true},
+				"previously": {"hero": {"alive": false, "respawn_seconds": 1, "health": 0}}
+			}`,
+			want: []Event{{Kind: EventHeroRespawned, GameTime: 634}},
+		},
+		{
+			name: "level up",
+			prev: `{"hero": {"id": 13, "level": 1}}`,
+			curr: `{
+				"map": {"game_time": 12},
+				"hero": {"id": 13, "level": 30, "xp": 64400, "alive": true},
+				"previously": {
+					"player": {"commands_issued": 11},
+					"hero": {"level": 1, "xp": 0, "health": 494, "max_health": 494, "mana": 351, "max_mana": 351, "attributes_level": 0}
+				}
+			}`,
+			want: []Event{{Kind: EventLevelUp, Value: 30, GameTime: 12}},
+		},
+		{
+			name: "ability levelled",
+			prev: `{"hero": {"id": 13, "level": 2}}`,
+			curr: `{
+				"map": {"game_time": 95},
+				"hero": {"id": 13, "level": 2, "alive": true},
+				"abilities": {
+					"ability0": {"name": "puck_illusory_orb", "level": 1},
+					"ability1": {"name": "puck_waning_rift", "level": 1},
+					"ability2": {"name": "puck_phase_shift", "level": 0}
+				},
+				"previously": {"abilities": {"ability1": {"level": 0, "can_cast": false}}}
+			}`,
+			want: []Event{{Kind: EventAbilityLevelled, Name: "puck_waning_rift", Value: 1, GameTime: 95}},
+		},
+		{
+			name: "item bought",
+			prev: `{"hero": {"id": 13}}`,
+			curr: `{
+				"map": {"game_time": -80},
+				"player": {"gold": 510},
+				"hero": {"id": 13, "alive": true},
+				"items": {
+					"slot0": {"name": "item_tango", "purchaser": 1, "charges": 3},
+					"slot1": {"name": "empty"},
+					"stash0": {"name": "empty"}
+				},
+				"previously": {
+					"player": {"gold": 600, "gold_unreliable": 600},
+					"items": {"slot0": {"name": "empty"}}
+				}
+			}`,
+			want: []Event{
+				{Kind: EventGoldSpent, Value: 90, GameTime: -80},
+				{Kind: EventItemPurchased, Name: "item_tango", GameTime: -80},
+			},
+		},
+		{
+			name: "item sold to empty",
+			prev: `{"hero": {"id": 13}}`,
+			curr: `{
+				"map": {"game_time": 1200},
+				"hero": {"id": 13, "alive": true},
+				"items": {
+					"slot0": {"name": "item_blink"},
+					"slot1": {"name": "empty"}
+				},
+				"previously": {"items": {"slot1": {"name": "item_branches", "purchaser": 1, "can_cast": false}}}
+			}`,
+			want: []Event{{Kind: EventItemSold, Name: "item_branches", GameTime: 1200}},
+		},
+		{
+			name: "item moved between slots",
+			prev: `{"hero": {"id": 13}}`,
+			curr: `{
+				"hero": {"id": 13, "alive": true},
+				"items": {
+					"slot0": {"name": "empty"},
+					"slot3": {"name": "item_blink"}
+				},
+				"previously": {"items": {"slot0": {"name": "item_blink"}, "slot3": {"name": "empty"}}}
+			}`,
+			want: nil,
+		},
+		{
+			name: "bare true delta",
+			prev: `{"hero": {"id": 13}}`,
+			curr: `{
+				"player": {"team_name": "radiant", "gold": 600},
+				"hero": {"id": 13, "alive": true},
+				"previously": {"player": {"kills": 0, "gold": 600}, "hero": true}
+			}`,
+			want: nil,
+		},
+		{
+			name: "hero added after a reconnect",
+			curr: `{
+				"map": {"game_time": 3},
+				"hero": {"id": 13, "name": "npc_dota_hero_puck", "level": 1, "alive": true},
+				"abilities": {
+					"ability0": {"name": "puck_illusory_orb", "level": 0},
+					"ability1": {"name": "puck_puckish", "level": 1}
+				},
+				"items": {
+					"slot0": {"name": "empty"},
+					"teleport0": {"name": "item_tpscroll"}
+				},
+				"added": {
+					"hero": {"level": true, "alive": true},
+					"abilities": {"ability0": true, "ability1": true},
+					"items": {"slot0": true, "teleport0": true}
+				}
+			}`,
+			want: []Event{
+				{Kind: EventHeroSelected, Value: 13, GameTime: 3},
+				{Kind: EventAbilityLevelled, Name: "puck_puckish", Value: 1, GameTime: 3},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prev *Payload
+			if tt.prev != "" {
+				prev = decodePayload(t, tt.prev)
+			}
+			got := Events(prev, decodePayload(t, tt.curr))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Events() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func decodePayload(t *testing.T, data string) *Payload {
+	t.Helper()
+	var p Payload
+	if err := json.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatal(err)
+	}
+	return &p
+}
@@ -0,0 +1,94 @@
+package gsi
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const emptyItemName = "empty"
+
+type Item struct {
+	Name         string `json:"name"`
+	Purchaser    int    `json:"purchaser"`
+	ItemLevel    int    `json:"item_level"`
+	ContainsRune string `json:"contains_rune"`
+	CanCast      bool   `json:"can_cast"`
+	Cooldown     int    `json:"cooldown"`
+	MaxCooldown  int    `json:"max_cooldown"`
+	Passive      bool   `json:"passive"`
+	ItemCharges  int    `json:"item_charges"`
+	Charges      int    `json:"charges"`
+}
+
+func (i Item) Empty() bool {
+	return i.Name == "" || i.Name == emptyItemName
+}
+
+// Items is the "items" block split by slot kind. Slots keep the order of their
+// numeric suffix, so Inventory[6..8] is the backpack and PreservedNeutral[0] is
+// preserved_neutral6.
+type Items struct {
+	Inventory        []Item
+	Stash            []Item
+	Teleport         Item
+	Neutral          []Item
+	PreservedNeutral []Item
+
+	// slots keeps the raw GSI keys so deltas can be matched back to a slot.
+	slots map[string]Item
+}
+
+func (it *Items) UnmarshalJSON(data []byte) error {
+	var raw map[string]Item
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*it = Items{
+		Inventory:        collectSlots(raw, "slot"),
+		Stash:            collectSlots(raw, "stash"),
+		Neutral:          collectSlots(raw, "neutral"),
+		PreservedNeutral: collectSlots(raw, "preserved_neutral"),
+		slots:            raw,
+	}
+	if tp, ok := raw["teleport0"]; ok {
+		it.Teleport = tp
+	}
+	return nil
+}
+
+// collectSlots picks the entries whose key is prefix followed by a number and
+// returns them ordered by that number.
+func collectSlots[T any](raw map[string]T, prefix string) []T {
+	type slot struct {
+		idx  int
+		item T
+	}
+
+	slots := make([]slot, 0, len(raw))
+	for key, item := range raw {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimPrefix(key, prefix))
+		if err != nil {
+			continue
+		}
+		slots = append(slots, slot{idx: idx, item: item})
+	}
+	if len(slots) == 0 {
+		return nil
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].idx < slots[j].idx
+	})
+
+	out := make([]T, 0, len(slots))
+	for _, s := range slots {
+		out = append(out, s.item)
+	}
+	return out
+}
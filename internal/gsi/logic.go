@@ -1,11 +1,27 @@
 package gsi
 
-import "overlay/internal/state"
+import (
+	"time"
 
-func (s *Server) handle(p *Payload, st *state.GameState, onEnemyHero func(int)) {
+	"overlay/internal/state"
+)
+
+func (s *Server) handle(p *Payload) []Event {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	st := s.st
+
+	var events []Event
+	if st.ObserveMatch(p.Map.MatchID, p.Map.GameState, time.Now()) {
+		// Deltas against the last match are meaningless.
+		s.prev = nil
+		events = append(events, Event{Kind: EventMatchStarted, Name: p.Map.MatchID, GameTime: p.Map.GameTime})
+	}
+	if st.SetGSIAccount(p.Player.AccountID) {
+		events = append(events, Event{Kind: EventPlayerChanged, Name: p.Player.AccountID, GameTime: p.Map.GameTime})
+	}
+
 	st.SetGSISnapshot(
 		p.Map.Phase,
 		p.Map.MatchID,
@@ -30,17 +46,141 @@ func (s *Server) handle(p *Payload, st *state.GameState, onEnemyHero func(int))
 		p.Player.GPM,
 		p.Player.XPM,
 	)
+	st.SetGSIItems(toStateItems(p.Items))
+	st.SetGSIHero(toStateHeroStatus(p), toStateAbilities(p.Abilities))
+
+	st.SetGSITeam(p.Player.Team)
+	if len(p.Draft.PicksBans) > 0 {
+		st.RecordDraft(toStateDraft(p), time.Now(), p.Map.ClockTime)
+	}
 
-	if s.prev == nil {
-		s.prev = p
-		return
+	turnTeam := ""
+	if p.Map.GameState == state.GameStateHeroSelection && p.Draft.ActiveTeam != 0 {
+		turnTeam = draftTeamName(p.Draft.ActiveTeam)
+	}
+	if st.SetDraftTurn(turnTeam, !p.Draft.Pick) {
+		turn := "pick"
+		if turnTeam != "" && !p.Draft.Pick {
+			turn = "ban"
+		}
+		events = append(events, Event{Kind: EventDraftTurn, Name: turn, GameTime: p.Map.GameTime})
 	}
 
-	prevHero := s.prev.Hero.ID
-	currHero := p.Hero.ID
-	if currHero > 0 && currHero != prevHero {
-		onEnemyHero(currHero)
+	events = append(events, Events(s.prev, p)...)
+
+	allies, enemies := classifyHeroes(p)
+	for _, id := range allies {
+		if st.AddAllyHeroByID(id) {
+			events = append(events, Event{Kind: EventAllyPicked, Value: id, GameTime: p.Map.GameTime})
+		}
+	}
+	for _, id := range enemies {
+		if st.AddEnemyHeroByID(id) {
+			events = append(events, Event{Kind: EventEnemyPicked, Value: id, GameTime: p.Map.GameTime})
+			if s.onEnemyHero != nil {
+				s.onEnemyHero(id)
+			}
+		}
 	}
 
 	s.prev = p
+	return events
+}
+
+func toStateDraft(p *Payload) []state.DraftEntry {
+	out := make([]state.DraftEntry, 0, len(p.Draft.PicksBans))
+	for _, pb := range p.Draft.PicksBans {
+		if pb.HeroID <= 0 {
+			continue
+		}
+		out = append(out, state.DraftEntry{
+			HeroID: pb.HeroID,
+			IsPick: pb.IsPick,
+			Team:   draftTeamName(pb.Team),
+		})
+	}
+	return out
+}
+
+func toStateHeroStatus(p *Payload) state.HeroStatus {
+	h := p.Hero
+	return state.HeroStatus{
+		Alive:           h.Alive,
+		RespawnSeconds:  h.RespawnSeconds,
+		BuybackCost:     h.BuybackCost,
+		BuybackCooldown: h.BuybackCooldown,
+		Stunned:         h.Stunned,
+		Silenced:        h.Silenced,
+		Hexed:           h.Hexed,
+		Disarmed:        h.Disarmed,
+		Muted:           h.Muted,
+		Break:           h.Break,
+		MagicImmune:     h.MagicImmune,
+		Smoked:          h.Smoked,
+		AghanimsScepter: h.AghanimsScepter,
+		AghanimsShard:   h.AghanimsShard,
+		Talents: []bool{
+			h.Talent1, h.Talent2, h.Talent3, h.Talent4,
+			h.Talent5, h.Talent6, h.Talent7, h.Talent8,
+		},
+	}
+}
+
+func toStateAbilities(abilities Abilities) []state.Ability {
+	if len(abilities) == 0 {
+		return nil
+	}
+	out := make([]state.Ability, 0, len(abilities))
+	for _, a := range abilities {
+		out = append(out, state.Ability{
+			Name:        a.Name,
+			Level:       a.Level,
+			CanCast:     a.CanCast,
+			Passive:     a.Passive,
+			Cooldown:    a.Cooldown,
+			MaxCooldown: a.MaxCooldown,
+			Ultimate:    a.Ultimate,
+		})
+	}
+	return out
+}
+
+func toStateItems(items Items) state.Items {
+	return state.Items{
+		Inventory:        toStateItemList(items.Inventory),
+		Stash:            toStateItemList(items.Stash),
+		Teleport:         toStateItem(items.Teleport),
+		Neutral:          toStateItemList(items.Neutral),
+		PreservedNeutral: toStateItemList(items.PreservedNeutral),
+	}
+}
+
+func toStateItemList(items []Item) []state.Item {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]state.Item, 0, len(items))
+	for _, it := range items {
+		out = append(out, toStateItem(it))
+	}
+	return out
+}
+
+func toStateItem(it Item) state.Item {
+	if it.Empty() {
+		return state.Item{}
+	}
+	charges := it.Charges
+	if charges == 0 {
+		charges = it.ItemCharges
+	}
+	return state.Item{
+		Name:        it.Name,
+		Charges:     charges,
+		Cooldown:    it.Cooldown,
+		MaxCooldown: it.MaxCooldown,
+		CanCast:     it.CanCast,
+		Passive:     it.Passive,
+		Purchaser:   it.Purchaser,
+	}
 }
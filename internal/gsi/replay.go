@@ -0,0 +1,121 @@
+package gsi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+// ReplayHeader marks payloads sent by a replay. The server processes them
+// as usual but does not record them, so a replay never grows the recording
+// it is played from.
+const ReplayHeader = "X-Overlay-Replay"
+
+// LogRecord is one payload from a gsi_log.txt recording.
+type LogRecord struct {
+	At   time.Time
+	Body []byte
+}
+
+// ReadLog parses the "<RFC3339 timestamp> <json>" format the server writes to
+// gsi_log.txt. Bodies are often pretty-printed over many lines, so a record
+// runs until the next line that starts with a timestamp.
+func ReadLog(r io.Reader) ([]LogRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var (
+		records []LogRecord
+		cur     *LogRecord
+		body    bytes.Buffer
+	)
+	flush := func() {
+		if cur == nil {
+			return
+		}
+		cur.Body = bytes.TrimSpace(append([]byte(nil), body.Bytes()...))
+		if len(cur.Body) > 0 {
+			records = append(records, *cur)
+		}
+		cur = nil
+		body.Reset()
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if at, rest, ok := splitLogLine(line); ok {
+			flush()
+			cur = &LogRecord{At: at}
+			body.WriteString(rest)
+			body.WriteByte('\n')
+			continue
+		}
+		if cur != nil {
+			body.WriteString(line)
+			body.WriteByte('\n')
+		}
+	}
+	flush()
+	return records, scanner.Err()
+}
+
+func splitLogLine(line string) (time.Time, string, bool) {
+	stamp, rest, ok := strings.Cut(line, " ")
+	if !ok {
+		return time.Time{}, "", false
+	}
+	at, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		return time.Time{}, "", false
+	}
+	return at, rest, true
+}
+
+// Replay hands records to send in order, waiting the recorded gap between
+// them divided by speed. speed <= 0 sends them back to back.
+func Replay(ctx context.Context, records []LogRecord, speed float64, send func(LogRecord) error) error {
+	for i, rec := range records {
+		if i > 0 && speed > 0 {
+			gap := rec.At.Sub(records[i-1].At)
+			if gap > 0 {
+				timer := time.NewTimer(time.Duration(float64(gap) / speed))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				case <-timer.C:
+				}
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := send(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WithToken rewrites the auth token of a recorded payload. Recordings have
+// the token redacted, so replays need the current one put back.
+func WithToken(body []byte, token string) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+	auth, err := json.Marshal(map[string]string{"token": token})
+	if err != nil {
+		return body
+	}
+	obj["auth"] = auth
+	out, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return out
+}
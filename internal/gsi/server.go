@@ -1,9 +1,12 @@
 package gsi
 
 import (
+	"bytes"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"sync"
@@ -16,44 +19,140 @@ type Server struct {
 	mu       sync.Mutex
 	prev     *Payload
 	lastSeen time.Time
+
+	st          *state.GameState
+	token       string
+	rawLogPath  string
+	onEnemyHero func(heroID int)
+	onSeen      func()
+
+	subMu     sync.Mutex
+	subs      map[int]func(Event)
+	nextSubID int
 }
 
 type snapshotResponse struct {
-	Status       string    `json:"status"`
-	GSIStatus    string    `json:"gsi_status"`
-	GSILastAt    time.Time `json:"gsi_last_at"`
-	GSIMatchID   string    `json:"gsi_match_id"`
-	GSIMapPhase  string    `json:"gsi_map_phase"`
-	GSIMapName   string    `json:"gsi_map_name"`
-	GSIHeroID    int       `json:"gsi_hero_id"`
-	GSIHeroName  string    `json:"gsi_hero_name"`
-	GSIHeroLevel int       `json:"gsi_hero_level"`
-	GSIHeroHP    int       `json:"gsi_hero_hp"`
-	GSIHeroHPMax int       `json:"gsi_hero_hp_max"`
-	GSIHeroMP    int       `json:"gsi_hero_mp"`
-	GSIHeroMPMax int       `json:"gsi_hero_mp_max"`
-	GSIKills     int       `json:"gsi_kills"`
-	GSIDeaths    int       `json:"gsi_deaths"`
-	GSIAssists   int       `json:"gsi_assists"`
-	GSILastHits  int       `json:"gsi_last_hits"`
-	GSIDenies    int       `json:"gsi_denies"`
-	GSIGold      int       `json:"gsi_gold"`
-	GSIGoldR     int       `json:"gsi_gold_r"`
-	GSIGoldU     int       `json:"gsi_gold_u"`
-	GSIGPM       int       `json:"gsi_gpm"`
-	GSIXPM       int       `json:"gsi_xpm"`
+	Status         string              `json:"status"`
+	GSITeam        string              `json:"gsi_team"`
+	AllyHeroes     []int               `json:"ally_hero_ids"`
+	EnemyHeroes    []int               `json:"enemy_hero_ids"`
+	GSIStatus      string              `json:"gsi_status"`
+	GSILastAt      time.Time           `json:"gsi_last_at"`
+	GSIMatchID     string              `json:"gsi_match_id"`
+	GSIMapPhase    string              `json:"gsi_map_phase"`
+	GSIMapName     string              `json:"gsi_map_name"`
+	GSIHeroID      int                 `json:"gsi_hero_id"`
+	GSIHeroName    string              `json:"gsi_hero_name"`
+	GSIHeroLevel   int                 `json:"gsi_hero_level"`
+	GSIHeroHP      int                 `json:"gsi_hero_hp"`
+	GSIHeroHPMax   int                 `json:"gsi_hero_hp_max"`
+	GSIHeroMP      int                 `json:"gsi_hero_mp"`
+	GSIHeroMPMax   int                 `json:"gsi_hero_mp_max"`
+	GSIKills       int                 `json:"gsi_kills"`
+	GSIDeaths      int                 `json:"gsi_deaths"`
+	GSIAssists     int                 `json:"gsi_assists"`
+	GSILastHits    int                 `json:"gsi_last_hits"`
+	GSIDenies      int                 `json:"gsi_denies"`
+	GSIGold        int                 `json:"gsi_gold"`
+	GSIGoldR       int                 `json:"gsi_gold_r"`
+	GSIGoldU       int                 `json:"gsi_gold_u"`
+	GSIGPM         int                 `json:"gsi_gpm"`
+	GSIXPM         int                 `json:"gsi_xpm"`
+	GSIItems       state.Items         `json:"gsi_items"`
+	GSIHero        state.HeroStatus    `json:"gsi_hero_status"`
+	GSIAbilities   []state.Ability     `json:"gsi_abilities"`
+	Draft          []state.DraftEntry  `json:"draft"`
+	GameState      string              `json:"game_state"`
+	MatchHistory   []state.MatchRecord `json:"match_history"`
+	MatchupSource  string              `json:"matchup_source"`
+	GSIAccountID   string              `json:"gsi_account_id"`
+	HeroPool       []int               `json:"hero_pool"`
+	DraftTurnTeam  string              `json:"draft_turn_team"`
+	DraftBanning   bool                `json:"draft_banning"`
+	BanSuggestions []state.ScoredHero  `json:"ban_suggestions"`
+	SetupChecks    []state.SetupCheck  `json:"setup_checks"`
+}
+
+// NewServer builds a GSI endpoint that only accepts payloads carrying token,
+// the value of "auth" { "token" } in the game's cfg file.
+func NewServer(st *state.GameState, token string, onEnemyHero func(heroID int), onSeen func()) *Server {
+	return &Server{
+		st:          st,
+		token:       token,
+		rawLogPath:  "gsi_log.txt",
+		onEnemyHero: onEnemyHero,
+		onSeen:      onSeen,
+		subs:        make(map[int]func(Event)),
+	}
+}
+
+// SetRawLog changes where accepted payloads are recorded; "" turns the
+// recording and its console echo off.
+func (s *Server) SetRawLog(path string) {
+	s.rawLogPath = path
+}
+
+// Subscribe registers fn for every event derived from incoming payloads.
+// fn runs on the request goroutine, so it must not block for long.
+func (s *Server) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s.subMu.Lock()
+	id := s.nextSubID
+	s.nextSubID++
+	s.subs[id] = fn
+	s.subMu.Unlock()
+
+	return func() {
+		s.subMu.Lock()
+		delete(s.subs, id)
+		s.subMu.Unlock()
+	}
 }
 
-func ListenAndServe(
-	addr string,
-	onEnemyHero func(heroID int),
-	onSeen func(),
-	st *state.GameState,
-) error {
+func (s *Server) publish(events []Event) {
+	if len(events) == 0 {
+		return
+	}
+	s.subMu.Lock()
+	subs := make([]func(Event), 0, len(s.subs))
+	for _, fn := range s.subs {
+		subs = append(subs, fn)
+	}
+	s.subMu.Unlock()
 
-	s := &Server{}
+	for _, ev := range events {
+		for _, fn := range subs {
+			fn(ev)
+		}
+	}
+}
 
-	return http.ListenAndServe(addr, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// DialAddr is where a client on this machine reaches a server listening on
+// addr: an empty or unspecified host (":3001", "0.0.0.0:3001") becomes
+// 127.0.0.1.
+func DialAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// ServerURL is the root URL of a server listening on addr, for clients on
+// this machine such as Dota itself.
+func ServerURL(addr string) string {
+	return "http://" + DialAddr(addr) + "/"
+}
+
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+func (s *Server) Handler() http.Handler {
+	st := s.st
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodGet {
 			switch r.URL.Path {
 			case "/":
@@ -61,35 +160,13 @@ func ListenAndServe(
 				_, _ = w.Write([]byte("ok"))
 				return
 			case "/snapshot":
-				snap := st.Snapshot(0)
-				resp := snapshotResponse{
-					Status:       snap.Status,
-					GSIStatus:    snap.GSIStatus,
-					GSILastAt:    snap.GSILastAt,
-					GSIMatchID:   snap.GSIMatchID,
-					GSIMapPhase:  snap.GSIMapPhase,
-					GSIMapName:   snap.GSIMapName,
-					GSIHeroID:    snap.GSIHeroID,
-					GSIHeroName:  snap.GSIHeroName,
-					GSIHeroLevel: snap.GSIHeroLevel,
-					GSIHeroHP:    snap.GSIHeroHP,
-					GSIHeroHPMax: snap.GSIHeroHPMax,
-					GSIHeroMP:    snap.GSIHeroMP,
-					GSIHeroMPMax: snap.GSIHeroMPMax,
-					GSIKills:     snap.GSIKills,
-					GSIDeaths:    snap.GSIDeaths,
-					GSIAssists:   snap.GSIAssists,
-					GSILastHits:  snap.GSILastHits,
-					GSIDenies:    snap.GSIDenies,
-					GSIGold:      snap.GSIGold,
-					GSIGoldR:     snap.GSIGoldR,
-					GSIGoldU:     snap.GSIGoldU,
-					GSIGPM:       snap.GSIGPM,
-					GSIXPM:       snap.GSIXPM,
-				}
+				resp := buildSnapshotResponse(st.Snapshot(0))
 				w.Header().Set("Content-Type", "application/json")
 				_ = json.NewEncoder(w).Encode(resp)
 				return
+			case "/stream":
+				s.serveStream(w, r)
+				return
 			default:
 				w.WriteHeader(http.StatusNotFound)
 				return
@@ -104,14 +181,6 @@ func ListenAndServe(
 		defer r.Body.Close()
 
 		raw, _ := io.ReadAll(r.Body)
-		if len(raw) > 0 {
-			ts := time.Now().Format(time.RFC3339)
-			if logFile, err := os.OpenFile("gsi_log.txt", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err == nil {
-				logFile.WriteString(ts + " " + string(raw) + "\n")
-				logFile.Close()
-			}
-			fmt.Println("GSI:", string(raw))
-		}
 
 		var p Payload
 		if err := json.Unmarshal(raw, &p); err != nil {
@@ -119,13 +188,89 @@ func ListenAndServe(
 			return
 		}
 
-		if p.Auth.Token != "" {
-			fmt.Println("GSI auth token:", p.Auth.Token)
+		if !s.authorized(p.Auth.Token) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
 		}
 
-		onSeen()
-		s.handle(&p, st, onEnemyHero)
+		if len(raw) > 0 && s.rawLogPath != "" && r.Header.Get(ReplayHeader) == "" {
+			logged := redactToken(raw, p.Auth.Token)
+			ts := time.Now().Format(time.RFC3339)
+			if logFile, err := os.OpenFile(s.rawLogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644); err == nil {
+				logFile.WriteString(ts + " " + string(logged) + "\n")
+				logFile.Close()
+			}
+			fmt.Println("GSI:", string(logged))
+		}
+
+		if s.onSeen != nil {
+			s.onSeen()
+		}
+		s.publish(s.handle(&p))
 
 		w.WriteHeader(http.StatusOK)
-	}))
+	})
+}
+
+func buildSnapshotResponse(snap state.Snapshot) snapshotResponse {
+	return snapshotResponse{
+		Status:         snap.Status,
+		GSITeam:        snap.GSITeam,
+		AllyHeroes:     snap.AllyHeroesIDs,
+		EnemyHeroes:    snap.EnemyHeroesIDs,
+		GSIStatus:      snap.GSIStatus,
+		GSILastAt:      snap.GSILastAt,
+		GSIMatchID:     snap.GSIMatchID,
+		GSIMapPhase:    snap.GSIMapPhase,
+		GSIMapName:     snap.GSIMapName,
+		GSIHeroID:      snap.GSIHeroID,
+		GSIHeroName:    snap.GSIHeroName,
+		GSIHeroLevel:   snap.GSIHeroLevel,
+		GSIHeroHP:      snap.GSIHeroHP,
+		GSIHeroHPMax:   snap.GSIHeroHPMax,
+		GSIHeroMP:      snap.GSIHeroMP,
+		GSIHeroMPMax:   snap.GSIHeroMPMax,
+		GSIKills:       snap.GSIKills,
+		GSIDeaths:      snap.GSIDeaths,
+		GSIAssists:     snap.GSIAssists,
+		GSILastHits:    snap.GSILastHits,
+		GSIDenies:      snap.GSIDenies,
+		GSIGold:        snap.GSIGold,
+		GSIGoldR:       snap.GSIGoldR,
+		GSIGoldU:       snap.GSIGoldU,
+		GSIGPM:         snap.GSIGPM,
+		GSIXPM:         snap.GSIXPM,
+		GSIItems:       snap.GSIItems,
+		GSIHero:        snap.GSIHeroStatus,
+		GSIAbilities:   snap.GSIAbilities,
+		Draft:          snap.Draft,
+		GameState:      snap.GameState,
+		MatchHistory:   snap.MatchHistory,
+		MatchupSource:  snap.MatchupSource,
+		GSIAccountID:   snap.GSIAccountID,
+		HeroPool:       snap.HeroPool,
+		DraftTurnTeam:  snap.DraftTurnTeam,
+		DraftBanning:   snap.DraftBanning,
+		BanSuggestions: snap.BanSuggestions,
+		SetupChecks:    snap.SetupChecks,
+	}
+}
+
+func (s *Server) authorized(token string) bool {
+	if s.token == "" {
+		return true
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// redactToken keeps the auth token out of gsi_log.txt and stdout.
+func redactToken(raw []byte, token string) []byte {
+	if token == "" {
+		return raw
+	}
+	quoted, err := json.Marshal(token)
+	if err != nil {
+		return raw
+	}
+	return bytes.ReplaceAll(raw, quoted, []byte(`"<redacted>"`))
 }
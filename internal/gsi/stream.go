@@ -0,0 +1,71 @@
+package gsi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// streamMinInterval caps how often one client gets a snapshot; GSI can
+	// tick several times a second and the UI does not need every one.
+	streamMinInterval = 100 * time.Millisecond
+	streamHeartbeat   = 15 * time.Second
+)
+
+// serveStream pushes the snapshot as Server-Sent Events: one "snapshot" event
+// right away and another whenever the game state changes. Comment lines keep
+// idle connections alive.
+func (s *Server) serveStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	var last []byte
+	for {
+		// Grab the channel before the snapshot so no change slips between.
+		changed := s.st.Changed()
+
+		data, err := json.Marshal(buildSnapshotResponse(s.st.Snapshot(0)))
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(data, last) {
+			if _, err := fmt.Fprintf(w, "event: snapshot\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			flusher.Flush()
+			last = data
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+			continue
+		case <-changed:
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(streamMinInterval):
+		}
+	}
+}
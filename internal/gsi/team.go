@@ -0,0 +1,47 @@
+package gsi
+
+const (
+	TeamRadiant = "radiant"
+	TeamDire    = "dire"
+)
+
+// draftTeamName maps the numeric team of a draft entry to GSI's team_name.
+// The game uses 2/3 for radiant/dire; 0/1 shows up in OpenDota-style dumps.
+func draftTeamName(team int) string {
+	switch team {
+	case 0, 2:
+		return TeamRadiant
+	case 1, 3:
+		return TeamDire
+	}
+	return ""
+}
+
+// classifyHeroes splits every hero we can place from p into our team and the
+// enemy's. Our own hero is always an ally; draft picks are only usable once
+// GSI tells us which side we are on.
+func classifyHeroes(p *Payload) (allies, enemies []int) {
+	if p.Hero.ID > 0 {
+		allies = append(allies, p.Hero.ID)
+	}
+
+	ours := p.Player.Team
+	if ours != TeamRadiant && ours != TeamDire {
+		return allies, nil
+	}
+
+	for _, pb := range p.Draft.PicksBans {
+		if !pb.IsPick || pb.HeroID <= 0 {
+			continue
+		}
+		switch draftTeamName(pb.Team) {
+		case "":
+			continue
+		case ours:
+			allies = append(allies, pb.HeroID)
+		default:
+			enemies = append(enemies, pb.HeroID)
+		}
+	}
+	return allies, enemies
+}
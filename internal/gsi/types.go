@@ -14,6 +14,7 @@ type Payload struct {
 		Name      string `json:"name"`
 		GameTime  int    `json:"game_time"`
 		ClockTime int    `json:"clock_time"`
+		GameState string `json:"game_state"`
 	} `json:"map"`
 
 	Player struct {
@@ -51,16 +52,52 @@ type Payload struct {
 		XPos      int    `json:"xpos"`
 		YPos      int    `json:"ypos"`
 		Facet     int    `json:"facet"`
+
+		RespawnSeconds  int  `json:"respawn_seconds"`
+		BuybackCost     int  `json:"buyback_cost"`
+		BuybackCooldown int  `json:"buyback_cooldown"`
+		Silenced        bool `json:"silenced"`
+		Stunned         bool `json:"stunned"`
+		Disarmed        bool `json:"disarmed"`
+		MagicImmune     bool `json:"magicimmune"`
+		Hexed           bool `json:"hexed"`
+		Muted           bool `json:"muted"`
+		Break           bool `json:"break"`
+		Smoked          bool `json:"smoked"`
+		HasDebuff       bool `json:"has_debuff"`
+		AghanimsScepter bool `json:"aghanims_scepter"`
+		AghanimsShard   bool `json:"aghanims_shard"`
+		AttributesLevel int  `json:"attributes_level"`
+		Talent1         bool `json:"talent_1"`
+		Talent2         bool `json:"talent_2"`
+		Talent3         bool `json:"talent_3"`
+		Talent4         bool `json:"talent_4"`
+		Talent5         bool `json:"talent_5"`
+		Talent6         bool `json:"talent_6"`
+		Talent7         bool `json:"talent_7"`
+		Talent8         bool `json:"talent_8"`
 	} `json:"hero"`
 
+	Abilities Abilities `json:"abilities"`
+
+	Items Items `json:"items"`
+
 	Draft struct {
-		PicksBans []struct {
+		// ActiveTeam is whose turn it is (2 radiant, 3 dire; 0 when there is
+		// no draft) and Pick tells a pick turn from a ban turn.
+		ActiveTeam              int  `json:"activeteam"`
+		Pick                    bool `json:"pick"`
+		ActiveTeamTimeRemaining int  `json:"activeteam_time_remaining"`
+		PicksBans               []struct {
 			IsPick bool `json:"is_pick"`
 			HeroID int  `json:"hero_id"`
 			Team   int  `json:"team"`
 		} `json:"picks_bans"`
 	} `json:"draft"`
 
+	Previously Delta `json:"previously"`
+	Added      Delta `json:"added"`
+
 	Auth struct {
 		Token string `json:"token"`
 	} `json:"auth"`
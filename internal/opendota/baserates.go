@@ -0,0 +1,117 @@
+package opendota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// neutralRate is the base win rate of a hero nothing is known about.
+const neutralRate = 0.5
+
+// HeroStat is one row of /heroStats; only the public match totals are used.
+type HeroStat struct {
+	ID      int `json:"id"`
+	PubPick int `json:"pub_pick"`
+	PubWin  int `json:"pub_win"`
+}
+
+// BaseRates maps a hero to its overall win rate. Scorers shrink a hero's
+// matchup win rate toward its own base rate and measure advantage over it.
+type BaseRates map[int]float64
+
+// Of is the base win rate of heroID, or neutralRate if it is unknown.
+func (b BaseRates) Of(heroID int) float64 {
+	if rate, ok := b[heroID]; ok {
+		return rate
+	}
+	return neutralRate
+}
+
+// BaseRateSource serves every hero's overall win rate. The live Client,
+// the bundled dataset and FallbackSource all implement it.
+type BaseRateSource interface {
+	GetBaseRates(ctx context.Context) (BaseRates, error)
+}
+
+func (c *Client) GetHeroStats(ctx context.Context) ([]HeroStat, error) {
+	var stats []HeroStat
+	if err := c.get(ctx, "/heroStats", &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (c *Client) GetBaseRates(ctx context.Context) (BaseRates, error) {
+	stats, err := c.GetHeroStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return BaseRatesFromStats(stats), nil
+}
+
+func (c *CachedClient) GetBaseRates(ctx context.Context) (BaseRates, error) {
+	stats, _, err := fetchCached(ctx, c, "herostats", c.matchupsTTL, c.client.GetHeroStats)
+	if err != nil {
+		return nil, err
+	}
+	return BaseRatesFromStats(stats), nil
+}
+
+// StrongestHeroes ranks heroes by base win rate: a ranking that does not
+// depend on any lineup, for when there is nothing to counter yet.
+func StrongestHeroes(base BaseRates) []ScoredHero {
+	return rankScores(base, 1)
+}
+
+// BaseRatesFromStats is the public win rate of every hero with picks.
+func BaseRatesFromStats(stats []HeroStat) BaseRates {
+	rates := make(BaseRates, len(stats))
+	for _, s := range stats {
+		if s.PubPick > 0 {
+			rates[s.ID] = float64(s.PubWin) / float64(s.PubPick)
+		}
+	}
+	return rates
+}
+
+// GetBaseRates aggregates each hero's matchups: its wins over its games
+// against everyone.
+func (l *LocalMatchups) GetBaseRates(ctx context.Context) (BaseRates, error) {
+	rates := make(BaseRates, len(l.byHero))
+	for heroID, matchups := range l.byHero {
+		wins, games := 0, 0
+		for _, m := range matchups {
+			wins += m.Wins
+			games += m.GamesPlayed
+		}
+		if games > 0 {
+			rates[heroID] = float64(wins) / float64(games)
+		}
+	}
+	return rates, nil
+}
+
+// GetBaseRates asks the sources that know base rates in order and returns
+// the first non-empty answer.
+func (f *FallbackSource) GetBaseRates(ctx context.Context) (BaseRates, error) {
+	var errs []error
+	for _, src := range f.sources {
+		withRates, ok := src.Source.(BaseRateSource)
+		if !ok {
+			continue
+		}
+		rates, err := withRates.GetBaseRates(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", src.Name, err))
+			continue
+		}
+		if len(rates) > 0 {
+			return rates, nil
+		}
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("opendota: no base win rates")
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,77 @@
+package opendota
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	FetchedAt time.Time       `json:"fetched_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Cache keeps API responses as one JSON file per key under dir, so they
+// survive restarts. Entries read once stay in memory.
+type Cache struct {
+	dir string
+
+	mu  sync.Mutex
+	mem map[string]cacheEntry
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir, mem: make(map[string]cacheEntry)}
+}
+
+func (c *Cache) get(key string) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, ok := c.mem[key]; ok {
+		return entry, true
+	}
+
+	raw, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return cacheEntry{}, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return cacheEntry{}, false
+	}
+	c.mem[key] = entry
+	return entry, true
+}
+
+func (c *Cache) put(key string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	entry := cacheEntry{FetchedAt: time.Now(), Data: data}
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mem[key] = entry
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	// Write then rename so a crash never leaves a half-written entry.
+	tmp := c.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path(key))
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
@@ -0,0 +1,138 @@
+package opendota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHeroesTTL   = 7 * 24 * time.Hour
+	DefaultMatchupsTTL = 24 * time.Hour
+
+	revalidateTimeout = 20 * time.Second
+)
+
+// Origins reported by CachedClient for the source indicator.
+const (
+	OriginLive       = "live"
+	OriginCache      = "cache"
+	OriginStaleCache = "cache (stale)"
+)
+
+// CachedClient puts a Cache in front of Client with stale-while-revalidate
+// semantics: a fresh entry is served as is, a stale one is served right away
+// while a background request refreshes it, and only a missing entry waits
+// for the API. Stale entries are kept until a refresh succeeds, so an API
+// outage falls back to the last good data. Concurrent requests for the same
+// key share one API call.
+type CachedClient struct {
+	client      *Client
+	cache       *Cache
+	heroesTTL   time.Duration
+	matchupsTTL time.Duration
+
+	flight flightGroup
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+func NewCachedClient(client *Client, cache *Cache) *CachedClient {
+	return &CachedClient{
+		client:      client,
+		cache:       cache,
+		heroesTTL:   DefaultHeroesTTL,
+		matchupsTTL: DefaultMatchupsTTL,
+		refreshing:  make(map[string]bool),
+	}
+}
+
+func (c *CachedClient) GetHeroes(ctx context.Context) ([]Hero, error) {
+	heroes, _, err := fetchCached(ctx, c, "heroes", c.heroesTTL, c.client.GetHeroes)
+	return heroes, err
+}
+
+func (c *CachedClient) GetHeroMatchups(ctx context.Context, heroID int) ([]HeroMatchup, error) {
+	matchups, _, err := c.GetHeroMatchupsOrigin(ctx, heroID)
+	return matchups, err
+}
+
+// GetHeroMatchupsOrigin is GetHeroMatchups that also says whether the answer
+// came from the API or the cache.
+func (c *CachedClient) GetHeroMatchupsOrigin(ctx context.Context, heroID int) ([]HeroMatchup, string, error) {
+	key := fmt.Sprintf("matchups_%d", heroID)
+	return fetchCached(ctx, c, key, c.matchupsTTL, func(ctx context.Context) ([]HeroMatchup, error) {
+		return c.client.GetHeroMatchups(ctx, heroID)
+	})
+}
+
+func fetchCached[T any](
+	ctx context.Context,
+	c *CachedClient,
+	key string,
+	ttl time.Duration,
+	fetch func(ctx context.Context) (T, error),
+) (T, string, error) {
+	if entry, ok := c.cache.get(key); ok {
+		var value T
+		if err := json.Unmarshal(entry.Data, &value); err == nil {
+			if time.Since(entry.FetchedAt) < ttl {
+				return value, OriginCache, nil
+			}
+			c.revalidate(key, func(ctx context.Context) error {
+				_, err := c.flight.do(key, func() (any, error) {
+					fresh, err := fetch(ctx)
+					if err != nil {
+						return nil, err
+					}
+					return fresh, c.cache.put(key, fresh)
+				})
+				return err
+			})
+			return value, OriginStaleCache, nil
+		}
+	}
+
+	shared, err := c.flight.do(key, func() (any, error) {
+		value, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// A failed write only costs a refetch next time.
+		_ = c.cache.put(key, value)
+		return value, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, "", err
+	}
+	return shared.(T), OriginLive, nil
+}
+
+// revalidate runs refresh in the background unless one is already running
+// for key. Errors are dropped: the stale entry stays and is retried on the
+// next read.
+func (c *CachedClient) revalidate(key string, refresh func(ctx context.Context) error) {
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), revalidateTimeout)
+		defer cancel()
+		_ = refresh(ctx)
+	}()
+}
@@ -4,101 +4,158 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
 const defaultBaseURL = "https://api.opendota.com/api"
 
+const (
+	maxRetries     = 3
+	retryBaseDelay = 500 * time.Millisecond
+	retryMaxDelay  = 10 * time.Second
+	// maxRetryAfter caps what the server can ask us to wait; longer than
+	// that and the caller is better off with cached or bundled data.
+	maxRetryAfter = 60 * time.Second
+)
+
 type Client struct {
 	http    *http.Client
 	baseURL string
 	apiKey  string
+	limiter *rateLimiter
 }
 
 type Hero struct {
-	ID            int    `json:"id"`
-	Name          string `json:"name"`
-	LocalizedName string `json:"localized_name"`
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	LocalizedName string   `json:"localized_name"`
+	Roles         []string `json:"roles"`
 }
 
 func NewClient(apiKey string) *Client {
+	perMinute := freeCallsPerMinute
+	if apiKey != "" {
+		perMinute = keyedCallsPerMinute
+	}
 	return &Client{
 		http: &http.Client{
 			Timeout: 10 * time.Second,
 		},
 		baseURL: defaultBaseURL,
 		apiKey:  apiKey,
+		limiter: newRateLimiter(perMinute, 5),
 	}
 }
 
 func (c *Client) GetHeroMatchups(ctx context.Context, heroID int) ([]HeroMatchup, error) {
-	endpoint := fmt.Sprintf("%s/heroes/%d/matchups", c.baseURL, heroID)
-	u, err := url.Parse(endpoint)
-	if err != nil {
-		return nil, err
-	}
-
-	if c.apiKey != "" {
-		q := u.Query()
-		q.Set("api_key", c.apiKey)
-		u.RawQuery = q.Encode()
-	}
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("opendota: unexpected status %s", resp.Status)
-	}
-
 	var matchups []HeroMatchup
-	if err := json.NewDecoder(resp.Body).Decode(&matchups); err != nil {
+	if err := c.get(ctx, fmt.Sprintf("/heroes/%d/matchups", heroID), &matchups); err != nil {
 		return nil, err
 	}
 	return matchups, nil
 }
 
 func (c *Client) GetHeroes(ctx context.Context) ([]Hero, error) {
-	endpoint := fmt.Sprintf("%s/heroes", c.baseURL)
-	u, err := url.Parse(endpoint)
-	if err != nil {
+	var heroes []Hero
+	if err := c.get(ctx, "/heroes", &heroes); err != nil {
 		return nil, err
 	}
+	return heroes, nil
+}
 
+// get fetches baseURL+path into out. Every attempt goes through the rate
+// limiter; 429 and 5xx answers are retried with jittered exponential backoff,
+// or after Retry-After when the server sends one.
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	u, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return err
+	}
 	if c.apiKey != "" {
 		q := u.Query()
 		q.Set("api_key", c.apiKey)
 		u.RawQuery = q.Encode()
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		retryAfter, err := c.do(ctx, u.String(), out)
+		if err == nil || retryAfter < 0 || attempt >= maxRetries {
+			return err
+		}
+
+		wait := retryAfter
+		if wait == 0 {
+			wait = backoff(attempt)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// do makes one request. retryAfter is negative when the error is final, zero
+// when it is worth retrying after the usual backoff, and positive when the
+// server said how long to wait.
+func (c *Client) do(ctx context.Context, target string, out any) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
 	if err != nil {
-		return nil, err
+		return -1, err
 	}
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, err
+		return -1, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("opendota: unexpected status %s", resp.Status)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("opendota: unexpected status %s", resp.Status)
+		return -1, fmt.Errorf("opendota: unexpected status %s", resp.Status)
 	}
 
-	var heroes []Hero
-	if err := json.NewDecoder(resp.Body).Decode(&heroes); err != nil {
-		return nil, err
+	return -1, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// backoff is full jitter over an exponentially growing window.
+func backoff(attempt int) time.Duration {
+	window := retryBaseDelay << attempt
+	if window > retryMaxDelay {
+		window = retryMaxDelay
 	}
-	return heroes, nil
+	return time.Duration(rand.Int64N(int64(window))) + time.Millisecond
+}
+
+// parseRetryAfter reads either form of the header: delay in seconds or an
+// HTTP date. It returns 0 when the header is missing or unusable.
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	var wait time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		wait = time.Until(at)
+	}
+	if wait <= 0 {
+		return 0
+	}
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait
 }
@@ -0,0 +1,79 @@
+package opendota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// bundledSampleSize stands in for games_played, which the bundled dataset
+// does not carry. It is large enough to pass the usual minGames cutoffs.
+const bundledSampleSize = 1000
+
+type localMatchup struct {
+	HeroID  int     `json:"hero_id"`
+	WinRate float64 `json:"winrate"`
+}
+
+// LocalMatchups serves matchups from a file shaped like the bundled
+// matchups.json: hero_id -> [{hero_id, winrate}], where winrate is the key
+// hero's win rate against hero_id as a fraction (or a percentage).
+type LocalMatchups struct {
+	byHero map[int][]HeroMatchup
+}
+
+func LoadLocalMatchups(path string) (*LocalMatchups, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed map[string][]localMatchup
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("opendota: %s: %w", path, err)
+	}
+
+	byHero := make(map[int][]HeroMatchup, len(parsed))
+	for key, list := range parsed {
+		heroID, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		matchups := make([]HeroMatchup, 0, len(list))
+		for _, m := range list {
+			wr := m.WinRate
+			if wr > 1 {
+				wr /= 100
+			}
+			// The dataset writes 0 for pairs it has no numbers for.
+			if wr <= 0 || wr >= 1 {
+				continue
+			}
+			matchups = append(matchups, HeroMatchup{
+				HeroID:      m.HeroID,
+				GamesPlayed: bundledSampleSize,
+				Wins:        int(math.Round(wr * bundledSampleSize)),
+			})
+		}
+		byHero[heroID] = matchups
+	}
+	return &LocalMatchups{byHero: byHero}, nil
+}
+
+func (l *LocalMatchups) GetHeroMatchups(ctx context.Context, heroID int) ([]HeroMatchup, error) {
+	return append([]HeroMatchup(nil), l.byHero[heroID]...), nil
+}
+
+// Usable reports how many heroes have at least one matchup with data.
+func (l *LocalMatchups) Usable() int {
+	n := 0
+	for _, m := range l.byHero {
+		if len(m) > 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -1,8 +1,10 @@
 package opendota
 
 import (
+	"errors"
+	"fmt"
 	"sort"
-	"time"
+	"sync"
 )
 
 type HeroMatchup struct {
@@ -15,6 +17,10 @@ type CounterPick struct {
 	HeroID  int
 	Games   int
 	WinRate float64
+	// Score is what the picks are ranked by; see Scorer.
+	Score float64
+	// Confidence is the ± of WinRate at 95%.
+	Confidence float64
 }
 
 type ScoredHero struct {
@@ -22,7 +28,9 @@ type ScoredHero struct {
 	Score  float64
 }
 
-func CalculateCounters(matchups []HeroMatchup, minGames int, limit int) []CounterPick {
+// CalculateCounters lists the heroes that do best against the hero whose
+// matchups these are, ranked by scorer against each hero's base rate.
+func CalculateCounters(matchups []HeroMatchup, scorer Scorer, base BaseRates, minGames int, limit int) []CounterPick {
 	if minGames < 0 {
 		minGames = 0
 	}
@@ -32,19 +40,21 @@ func CalculateCounters(matchups []HeroMatchup, minGames int, limit int) []Counte
 		if m.GamesPlayed < minGames || m.GamesPlayed == 0 {
 			continue
 		}
-		winRateAgainst := 1 - float64(m.Wins)/float64(m.GamesPlayed)
+		counterWins := m.GamesPlayed - m.Wins
 		counters = append(counters, CounterPick{
-			HeroID:  m.HeroID,
-			Games:   m.GamesPlayed,
-			WinRate: winRateAgainst,
+			HeroID:     m.HeroID,
+			Games:      m.GamesPlayed,
+			WinRate:    float64(counterWins) / float64(m.GamesPlayed),
+			Score:      scorer(counterWins, m.GamesPlayed, base.Of(m.HeroID)),
+			Confidence: confidence(counterWins, m.GamesPlayed),
 		})
 	}
 
 	sort.Slice(counters, func(i, j int) bool {
-		if counters[i].WinRate == counters[j].WinRate {
+		if counters[i].Score == counters[j].Score {
 			return counters[i].Games > counters[j].Games
 		}
-		return counters[i].WinRate > counters[j].WinRate
+		return counters[i].Score > counters[j].Score
 	})
 
 	if limit > 0 && len(counters) > limit {
@@ -53,11 +63,18 @@ func CalculateCounters(matchups []HeroMatchup, minGames int, limit int) []Counte
 	return counters
 }
 
+// AnalyzeCounters scores every hero by its average scorer result over the
+// enemies, fetching up to workers enemies at a time. An enemy whose matchups
+// cannot be fetched is left out and the rest are still scored; the returned
+// error then lists the failures, and results are nil only if every enemy
+// failed.
 func AnalyzeCounters(
 	enemyIDs []int,
 	matchupsByEnemy func(enemyID int) ([]HeroMatchup, error),
+	scorer Scorer,
+	base BaseRates,
 	minGames int,
-	delay time.Duration,
+	workers int,
 ) ([]ScoredHero, error) {
 	if len(enemyIDs) == 0 {
 		return nil, nil
@@ -66,37 +83,65 @@ func AnalyzeCounters(
 		minGames = 0
 	}
 
+	fetched := fetchAll(enemyIDs, matchupsByEnemy, workers)
+
 	totalScores := make(map[int]float64)
+	var errs []error
+	scored := 0
 
-	for _, enemyID := range enemyIDs {
-		matchups, err := matchupsByEnemy(enemyID)
-		if err != nil {
-			return nil, err
+	for i, enemyID := range enemyIDs {
+		if err := fetched[i].err; err != nil {
+			errs = append(errs, fmt.Errorf("hero %d: %w", enemyID, err))
+			continue
 		}
+		scored++
 
-		for _, m := range matchups {
+		for _, m := range fetched[i].value {
 			if m.GamesPlayed < minGames || m.GamesPlayed == 0 {
 				continue
 			}
-			enemyWinRate := float64(m.Wins) / float64(m.GamesPlayed)
-			ourAdvantage := 1.0 - enemyWinRate
-			totalScores[m.HeroID] += ourAdvantage
-		}
-
-		if delay > 0 {
-			time.Sleep(delay)
+			totalScores[m.HeroID] += scorer(m.GamesPlayed-m.Wins, m.GamesPlayed, base.Of(m.HeroID))
 		}
 	}
-
-	results := make([]ScoredHero, 0, len(totalScores))
-	for id, score := range totalScores {
-		avgScore := score / float64(len(enemyIDs))
-		results = append(results, ScoredHero{HeroID: id, Score: avgScore})
+	if scored == 0 {
+		return nil, errors.Join(errs...)
 	}
 
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
+	return rankScores(totalScores, scored), errors.Join(errs...)
+}
 
-	return results, nil
+type fetchResult[T any] struct {
+	value []T
+	err   error
+}
+
+// fetchAll calls fetch for every id on at most workers goroutines and
+// returns the results in the order of ids.
+func fetchAll[T any](ids []int, fetch func(id int) ([]T, error), workers int) []fetchResult[T] {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(ids) {
+		workers = len(ids)
+	}
+
+	results := make([]fetchResult[T], len(ids))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				value, err := fetch(ids[i])
+				results[i] = fetchResult[T]{value: value, err: err}
+			}
+		}()
+	}
+	for i := range ids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
 }
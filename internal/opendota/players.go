@@ -0,0 +1,103 @@
+package opendota
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// Hero pool modes.
+const (
+	PoolOff      = "off"
+	PoolRestrict = "restrict"
+	PoolBoost    = "boost"
+)
+
+// PlayerHero is one row of /players/{account_id}/heroes.
+type PlayerHero struct {
+	HeroID     flexInt `json:"hero_id"`
+	Games      int     `json:"games"`
+	Win        int     `json:"win"`
+	LastPlayed int64   `json:"last_played"`
+}
+
+// flexInt takes both 12 and "12"; the players endpoints have sent hero IDs
+// either way.
+type flexInt int
+
+func (n *flexInt) UnmarshalJSON(raw []byte) error {
+	raw = bytes.Trim(raw, `"`)
+	v, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return err
+	}
+	*n = flexInt(v)
+	return nil
+}
+
+// GetPlayerHeroes lists the heroes accountID has played, as OpenDota knows
+// them from public match history.
+func (c *Client) GetPlayerHeroes(ctx context.Context, accountID string) ([]PlayerHero, error) {
+	if _, err := strconv.ParseUint(accountID, 10, 64); err != nil {
+		return nil, fmt.Errorf("opendota: bad account id %q", accountID)
+	}
+	var heroes []PlayerHero
+	if err := c.get(ctx, "/players/"+url.PathEscape(accountID)+"/heroes", &heroes); err != nil {
+		return nil, err
+	}
+	return heroes, nil
+}
+
+// GetPlayerHeroes is not cached: the hero pool file already keeps the result.
+func (c *CachedClient) GetPlayerHeroes(ctx context.Context, accountID string) ([]PlayerHero, error) {
+	return c.client.GetPlayerHeroes(ctx, accountID)
+}
+
+// PoolFromPlayerHeroes picks the size most played heroes with at least
+// minGames games.
+func PoolFromPlayerHeroes(heroes []PlayerHero, minGames int, size int) []int {
+	played := make([]PlayerHero, 0, len(heroes))
+	for _, h := range heroes {
+		if h.Games >= minGames && h.Games > 0 {
+			played = append(played, h)
+		}
+	}
+	sort.SliceStable(played, func(i, j int) bool { return played[i].Games > played[j].Games })
+	if size > 0 && len(played) > size {
+		played = played[:size]
+	}
+
+	pool := make([]int, 0, len(played))
+	for _, h := range played {
+		pool = append(pool, int(h.HeroID))
+	}
+	return pool
+}
+
+// ApplyPool restricts results to the pool or adds bonus to pool heroes,
+// depending on mode. An empty pool leaves results alone, so a private
+// profile does not blank the table.
+func ApplyPool(results []ScoredHero, pool []int, mode string, bonus float64) []ScoredHero {
+	if len(pool) == 0 || mode == PoolOff || mode == "" {
+		return results
+	}
+
+	out := make([]ScoredHero, 0, len(results))
+	for _, r := range results {
+		inPool := slices.Contains(pool, r.HeroID)
+		switch {
+		case mode == PoolRestrict && !inPool:
+			continue
+		case mode == PoolBoost && inPool:
+			r.Score += bonus
+		}
+		out = append(out, r)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
@@ -0,0 +1,57 @@
+package opendota
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestPoolFromPlayerHeroes(t *testing.T) {
+	var heroes []PlayerHero
+	// Hero IDs come both as numbers and as strings.
+	raw := `[
+		{"hero_id": 1, "games": 3},
+		{"hero_id": "2", "games": 40},
+		{"hero_id": 3, "games": 12},
+		{"hero_id": "4", "games": 25},
+		{"hero_id": 5, "games": 0}
+	]`
+	if err := json.Unmarshal([]byte(raw), &heroes); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := PoolFromPlayerHeroes(heroes, 5, 2), []int{2, 4}; !slices.Equal(got, want) {
+		t.Errorf("PoolFromPlayerHeroes(5 games, 2 heroes) = %v, want %v", got, want)
+	}
+	if got, want := PoolFromPlayerHeroes(heroes, 0, 0), []int{2, 4, 3, 1}; !slices.Equal(got, want) {
+		t.Errorf("PoolFromPlayerHeroes(no limits) = %v, want %v", got, want)
+	}
+}
+
+func TestApplyPool(t *testing.T) {
+	results := []ScoredHero{
+		{HeroID: 1, Score: 0.9},
+		{HeroID: 2, Score: 0.8},
+		{HeroID: 3, Score: 0.7},
+	}
+
+	tests := []struct {
+		name string
+		pool []int
+		mode string
+		want []ScoredHero
+	}{
+		{"off", []int{3}, PoolOff, results},
+		{"restrict", []int{3, 2}, PoolRestrict, []ScoredHero{{HeroID: 2, Score: 0.8}, {HeroID: 3, Score: 0.7}}},
+		{"restrict with an empty pool", nil, PoolRestrict, results},
+		{"boost", []int{3}, PoolBoost, []ScoredHero{{HeroID: 3, Score: 1.2}, {HeroID: 1, Score: 0.9}, {HeroID: 2, Score: 0.8}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyPool(slices.Clone(results), tt.pool, tt.mode, 0.5)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ApplyPool = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package opendota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OpenDota allows 60 calls a minute without an API key; keyed calls get a
+// far higher limit.
+const (
+	freeCallsPerMinute  = 60
+	keyedCallsPerMinute = 1200
+)
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refills at
+// perMinute/60 a second, and every call takes one.
+type rateLimiter struct {
+	mu       sync.Mutex
+	tokens   float64
+	burst    float64
+	perSec   float64
+	lastFill time.Time
+}
+
+func newRateLimiter(perMinute int, burst int) *rateLimiter {
+	return &rateLimiter{
+		tokens:   float64(burst),
+		burst:    float64(burst),
+		perSec:   float64(perMinute) / 60,
+		lastFill: time.Now(),
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a token if there is one, otherwise it says how long until
+// the next one.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.lastFill).Seconds() * l.perSec
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.lastFill = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.perSec * float64(time.Second))
+}
@@ -0,0 +1,137 @@
+package opendota
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Position is a Dota position, 1 (carry) to 5 (hard support). Zero means
+// "any".
+type Position int
+
+const (
+	PositionAny Position = iota
+	PositionCarry
+	PositionMid
+	PositionOfflane
+	PositionSoftSupport
+	PositionHardSupport
+)
+
+var positionNames = map[Position]string{
+	PositionCarry:       "carry",
+	PositionMid:         "mid",
+	PositionOfflane:     "offlane",
+	PositionSoftSupport: "support",
+	PositionHardSupport: "hard support",
+}
+
+func (p Position) String() string {
+	if name, ok := positionNames[p]; ok {
+		return name
+	}
+	return "any"
+}
+
+// ParsePosition accepts "1".."5" or a position name; "" is PositionAny.
+func ParsePosition(s string) (Position, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == "any" {
+		return PositionAny, nil
+	}
+	if n, err := strconv.Atoi(strings.TrimPrefix(s, "pos")); err == nil && n >= 1 && n <= 5 {
+		return Position(n), nil
+	}
+	s = strings.NewReplacer("_", " ", "-", " ").Replace(s)
+	for p, name := range positionNames {
+		if s == name {
+			return p, nil
+		}
+	}
+	return PositionAny, fmt.Errorf("opendota: unknown position %q", s)
+}
+
+// HeroPositions guesses the positions a hero plays from its OpenDota roles
+// (Carry, Support, Nuker, Initiator, Durable, ...). The API has no position
+// data, so this is a heuristic: nil means the roles say nothing useful.
+func HeroPositions(roles []string) []Position {
+	has := func(role string) bool { return slices.Contains(roles, role) }
+
+	var out []Position
+	if has("Carry") {
+		out = append(out, PositionCarry)
+	}
+	if (has("Nuker") && !has("Support")) || (has("Carry") && has("Escape")) {
+		out = append(out, PositionMid)
+	}
+	if has("Durable") || (has("Initiator") && !has("Support")) {
+		out = append(out, PositionOfflane)
+	}
+	if has("Support") && (has("Initiator") || has("Disabler") || has("Nuker") || has("Escape")) {
+		out = append(out, PositionSoftSupport)
+	}
+	if has("Support") {
+		out = append(out, PositionHardSupport)
+	}
+	return out
+}
+
+// CoveredPositions assigns each ally one position it can play, most
+// constrained heroes first, and returns the positions taken.
+func CoveredPositions(allies [][]Position) map[Position]bool {
+	order := make([][]Position, 0, len(allies))
+	for _, a := range allies {
+		if len(a) > 0 {
+			order = append(order, a)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(order[i]) < len(order[j]) })
+
+	covered := make(map[Position]bool)
+	for _, positions := range order {
+		for _, p := range positions {
+			if !covered[p] {
+				covered[p] = true
+				break
+			}
+		}
+	}
+	return covered
+}
+
+// ApplyRoles fits results to the team. With a preferred position, heroes
+// known not to play it are dropped. Without one, heroes that can fill a
+// position no ally covers yet get bonus added. Heroes without role data are
+// kept as is.
+func ApplyRoles(
+	results []ScoredHero,
+	positionsOf func(heroID int) []Position,
+	preferred Position,
+	covered map[Position]bool,
+	bonus float64,
+) []ScoredHero {
+	out := make([]ScoredHero, 0, len(results))
+	for _, r := range results {
+		positions := positionsOf(r.HeroID)
+		if len(positions) == 0 {
+			out = append(out, r)
+			continue
+		}
+		if preferred != PositionAny {
+			if slices.Contains(positions, preferred) {
+				out = append(out, r)
+			}
+			continue
+		}
+		if slices.ContainsFunc(positions, func(p Position) bool { return !covered[p] }) {
+			r.Score += bonus
+		}
+		out = append(out, r)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
@@ -0,0 +1,89 @@
+package opendota
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestHeroPositions(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []string
+		want  []Position
+	}{
+		{"carry", []string{"Carry", "Pusher"}, []Position{PositionCarry}},
+		{"escape carry", []string{"Carry", "Escape", "Nuker"}, []Position{PositionCarry, PositionMid}},
+		{"nuker", []string{"Nuker", "Disabler"}, []Position{PositionMid}},
+		{"offlaner", []string{"Initiator", "Durable", "Disabler"}, []Position{PositionOfflane}},
+		{"support", []string{"Support", "Disabler", "Nuker"}, []Position{PositionSoftSupport, PositionHardSupport}},
+		{"pure support", []string{"Support"}, []Position{PositionHardSupport}},
+		{"no useful roles", []string{"Pusher"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeroPositions(tt.roles); !slices.Equal(got, tt.want) {
+				t.Errorf("HeroPositions(%q) = %v, want %v", tt.roles, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoveredPositions(t *testing.T) {
+	// The pure carry takes position 1 first, so the flexible hero gets mid.
+	covered := CoveredPositions([][]Position{
+		{PositionCarry, PositionMid},
+		nil,
+		{PositionCarry},
+	})
+	want := map[Position]bool{PositionCarry: true, PositionMid: true}
+	if len(covered) != len(want) || !covered[PositionCarry] || !covered[PositionMid] {
+		t.Errorf("CoveredPositions = %v, want %v", covered, want)
+	}
+}
+
+func TestApplyRoles(t *testing.T) {
+	positions := map[int][]Position{
+		1: {PositionCarry},
+		2: {PositionSoftSupport, PositionHardSupport},
+		3: {PositionMid},
+		// Hero 4 has no role data.
+	}
+	positionsOf := func(heroID int) []Position { return positions[heroID] }
+	results := []ScoredHero{
+		{HeroID: 1, Score: 0.9},
+		{HeroID: 2, Score: 0.8},
+		{HeroID: 3, Score: 0.7},
+		{HeroID: 4, Score: 0.6},
+	}
+
+	tests := []struct {
+		name      string
+		preferred Position
+		covered   map[Position]bool
+		want      []ScoredHero
+	}{
+		{
+			name:      "preferred position",
+			preferred: PositionHardSupport,
+			want:      []ScoredHero{{HeroID: 2, Score: 0.8}, {HeroID: 4, Score: 0.6}},
+		},
+		{
+			name:    "uncovered position bonus",
+			covered: map[Position]bool{PositionCarry: true, PositionMid: true},
+			want: []ScoredHero{
+				{HeroID: 2, Score: 1.3},
+				{HeroID: 1, Score: 0.9},
+				{HeroID: 3, Score: 0.7},
+				{HeroID: 4, Score: 0.6},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplyRoles(slices.Clone(results), positionsOf, tt.preferred, tt.covered, 0.5)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ApplyRoles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package opendota
+
+import (
+	"fmt"
+	"math"
+)
+
+// Scoring strategies selectable from config.
+const (
+	ScoringRaw       = "raw"
+	ScoringWilson    = "wilson"
+	ScoringBayes     = "bayes"
+	ScoringAdvantage = "advantage"
+
+	DefaultScoring = ScoringBayes
+)
+
+const (
+	// wilsonZ is the z-score of a 95% interval.
+	wilsonZ = 1.96
+	// bayesPriorGames is how many games' worth of weight the base rate gets
+	// when shrinking a matchup win rate toward it.
+	bayesPriorGames = 200
+)
+
+// Scorer ranks a matchup from the counter's side: wins out of games against
+// the enemy (or with the ally), where baseline is the counter hero's own
+// overall win rate; see BaseRates. Higher is better.
+type Scorer func(wins, games int, baseline float64) float64
+
+// ScorerFor returns the scorer named by strategy; "" means DefaultScoring.
+func ScorerFor(strategy string) (Scorer, error) {
+	switch strategy {
+	case "":
+		return ScorerFor(DefaultScoring)
+	case ScoringRaw:
+		return rawScore, nil
+	case ScoringWilson:
+		return wilsonScore, nil
+	case ScoringBayes:
+		return bayesScore, nil
+	case ScoringAdvantage:
+		return advantageScore, nil
+	}
+	return nil, fmt.Errorf("opendota: unknown scoring strategy %q", strategy)
+}
+
+// rawScore is the plain win rate; small samples rank as high as big ones.
+func rawScore(wins, games int, baseline float64) float64 {
+	return float64(wins) / float64(games)
+}
+
+// wilsonScore is the lower bound of the 95% Wilson interval, so a win rate
+// only ranks high once enough games back it.
+func wilsonScore(wins, games int, baseline float64) float64 {
+	lo, _ := wilsonInterval(wins, games)
+	return lo
+}
+
+// bayesScore shrinks the win rate toward the hero's own base rate by
+// bayesPriorGames, so a few lucky games do not outrank a big sample.
+func bayesScore(wins, games int, baseline float64) float64 {
+	return (float64(wins) + bayesPriorGames*baseline) / float64(games+bayesPriorGames)
+}
+
+// advantageScore is how far the shrunk win rate beats the hero's own base
+// rate, so a hero that does well against everyone does not count as a
+// counter.
+func advantageScore(wins, games int, baseline float64) float64 {
+	return bayesScore(wins, games, baseline) - baseline
+}
+
+func wilsonInterval(wins, games int) (lo, hi float64) {
+	if games <= 0 {
+		return 0, 1
+	}
+	n := float64(games)
+	p := float64(wins) / n
+	z2 := wilsonZ * wilsonZ
+	center := (p + z2/(2*n)) / (1 + z2/n)
+	margin := wilsonZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / (1 + z2/n)
+	return center - margin, center + margin
+}
+
+// confidence is the half-width of the 95% interval around a win rate: the
+// "±" shown next to it.
+func confidence(wins, games int) float64 {
+	lo, hi := wilsonInterval(wins, games)
+	return (hi - lo) / 2
+}
@@ -0,0 +1,90 @@
+package opendota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// MatchupSource serves a hero's matchups. The live Client, the bundled
+// dataset and FallbackSource all implement it.
+type MatchupSource interface {
+	GetHeroMatchups(ctx context.Context, heroID int) ([]HeroMatchup, error)
+}
+
+// originSource is implemented by sources that know more precisely where an
+// answer came from, like CachedClient (live or cache). That origin is then
+// reported instead of the source name.
+type originSource interface {
+	GetHeroMatchupsOrigin(ctx context.Context, heroID int) ([]HeroMatchup, string, error)
+}
+
+type NamedSource struct {
+	Name   string
+	Source MatchupSource
+}
+
+// FallbackSource asks each source in order and returns the first non-empty
+// answer, e.g. "live API -> disk cache -> bundled dataset". onSource is told
+// which source answered so the UI can show where the numbers come from.
+type FallbackSource struct {
+	sources  []NamedSource
+	onSource func(name string)
+
+	mu   sync.Mutex
+	last string
+}
+
+func NewFallbackSource(onSource func(name string), sources ...NamedSource) *FallbackSource {
+	return &FallbackSource{sources: sources, onSource: onSource}
+}
+
+func (f *FallbackSource) GetHeroMatchups(ctx context.Context, heroID int) ([]HeroMatchup, error) {
+	var errs []error
+	for _, src := range f.sources {
+		matchups, origin, err := getMatchups(ctx, src, heroID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", src.Name, err))
+			continue
+		}
+		if len(matchups) == 0 {
+			continue
+		}
+		f.setLast(origin)
+		return matchups, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("opendota: no matchups for hero %d", heroID)
+	}
+	return nil, errors.Join(errs...)
+}
+
+func getMatchups(ctx context.Context, src NamedSource, heroID int) ([]HeroMatchup, string, error) {
+	if withOrigin, ok := src.Source.(originSource); ok {
+		matchups, origin, err := withOrigin.GetHeroMatchupsOrigin(ctx, heroID)
+		if origin == "" {
+			origin = src.Name
+		}
+		return matchups, origin, err
+	}
+	matchups, err := src.Source.GetHeroMatchups(ctx, heroID)
+	return matchups, src.Name, err
+}
+
+// LastSource is the name of the source that answered the latest request.
+func (f *FallbackSource) LastSource() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.last
+}
+
+func (f *FallbackSource) setLast(name string) {
+	f.mu.Lock()
+	changed := f.last != name
+	f.last = name
+	f.mu.Unlock()
+	if changed && f.onSource != nil {
+		f.onSource(name)
+	}
+}
@@ -0,0 +1,171 @@
+package opendota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// HeroDuo is how a hero does on the same team as another: Wins counts the
+// games they won together.
+type HeroDuo struct {
+	HeroID      int `json:"hero_id"`
+	GamesPlayed int `json:"games_played"`
+	Wins        int `json:"wins"`
+}
+
+// SynergySource serves a hero's duo stats.
+type SynergySource interface {
+	GetHeroDuos(ctx context.Context, heroID int) ([]HeroDuo, error)
+}
+
+// Weights balances the parts of a recommendation. Role and Pool are not
+// weights but bonuses; see ApplyRoles and ApplyPool.
+type Weights struct {
+	Counter float64
+	Synergy float64
+	Role    float64
+	Pool    float64
+}
+
+// LocalDuos serves duo stats from a file shaped like matchups.json:
+// hero_id -> [{hero_id, games_played, wins}].
+type LocalDuos struct {
+	byHero map[int][]HeroDuo
+}
+
+func LoadLocalDuos(path string) (*LocalDuos, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed map[string][]HeroDuo
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("opendota: %s: %w", path, err)
+	}
+
+	byHero := make(map[int][]HeroDuo, len(parsed))
+	for key, list := range parsed {
+		heroID, err := strconv.Atoi(key)
+		if err != nil {
+			continue
+		}
+		byHero[heroID] = list
+	}
+	return &LocalDuos{byHero: byHero}, nil
+}
+
+func (l *LocalDuos) GetHeroDuos(ctx context.Context, heroID int) ([]HeroDuo, error) {
+	return append([]HeroDuo(nil), l.byHero[heroID]...), nil
+}
+
+// AnalyzeSynergy scores every hero by its average scorer result alongside
+// the allies, the same way AnalyzeCounters does against enemies, including
+// the partial results on failure.
+func AnalyzeSynergy(
+	allyIDs []int,
+	duosByAlly func(allyID int) ([]HeroDuo, error),
+	scorer Scorer,
+	base BaseRates,
+	minGames int,
+	workers int,
+) ([]ScoredHero, error) {
+	if len(allyIDs) == 0 {
+		return nil, nil
+	}
+	if minGames < 0 {
+		minGames = 0
+	}
+
+	fetched := fetchAll(allyIDs, duosByAlly, workers)
+
+	totalScores := make(map[int]float64)
+	var errs []error
+	scored := 0
+
+	for i, allyID := range allyIDs {
+		if err := fetched[i].err; err != nil {
+			errs = append(errs, fmt.Errorf("hero %d: %w", allyID, err))
+			continue
+		}
+		scored++
+
+		for _, d := range fetched[i].value {
+			if d.GamesPlayed < minGames || d.GamesPlayed == 0 {
+				continue
+			}
+			totalScores[d.HeroID] += scorer(d.Wins, d.GamesPlayed, base.Of(d.HeroID))
+		}
+	}
+	if scored == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return rankScores(totalScores, scored), errors.Join(errs...)
+}
+
+// CombineScores blends counter and synergy results with w. A hero missing
+// from one list gets that list's average, so it is neither rewarded nor
+// punished for the gap. With either list empty the other is returned as is.
+func CombineScores(counters, synergy []ScoredHero, w Weights) []ScoredHero {
+	if len(synergy) == 0 || w.Synergy <= 0 {
+		return counters
+	}
+	if len(counters) == 0 || w.Counter <= 0 {
+		return synergy
+	}
+
+	counterBy, counterAvg := scoreIndex(counters)
+	synergyBy, synergyAvg := scoreIndex(synergy)
+
+	combined := make(map[int]float64, len(counterBy))
+	for id := range counterBy {
+		combined[id] = 0
+	}
+	for id := range synergyBy {
+		combined[id] = 0
+	}
+	for id := range combined {
+		c, ok := counterBy[id]
+		if !ok {
+			c = counterAvg
+		}
+		s, ok := synergyBy[id]
+		if !ok {
+			s = synergyAvg
+		}
+		combined[id] = (w.Counter*c + w.Synergy*s) / (w.Counter + w.Synergy)
+	}
+	return rankScores(combined, 1)
+}
+
+func scoreIndex(list []ScoredHero) (map[int]float64, float64) {
+	by := make(map[int]float64, len(list))
+	sum := 0.0
+	for _, h := range list {
+		by[h.HeroID] = h.Score
+		sum += h.Score
+	}
+	return by, sum / float64(len(list))
+}
+
+// rankScores turns summed scores into a list of averages over n, best first.
+func rankScores(totals map[int]float64, n int) []ScoredHero {
+	results := make([]ScoredHero, 0, len(totals))
+	for id, score := range totals {
+		results = append(results, ScoredHero{HeroID: id, Score: score / float64(n)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].HeroID < results[j].HeroID
+		}
+		return results[i].Score > results[j].Score
+	})
+	return results
+}
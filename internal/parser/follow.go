@@ -0,0 +1,290 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Polling and retry intervals of the follower. With change notifications
+// the follower still wakes every notifyTimeout to catch missed events.
+const (
+	defaultPollInterval = 100 * time.Millisecond
+	notifyTimeout       = time.Second
+	minBackoff          = 100 * time.Millisecond
+	maxBackoff          = 5 * time.Second
+)
+
+// maxPartialLine caps how much of an unterminated line is buffered; a line
+// longer than that is dropped as a whole.
+const maxPartialLine = 1 << 20
+
+// FollowOptions tune how Start follows console.log.
+type FollowOptions struct {
+	// PollInterval is how often the file is checked for new data when
+	// change notifications are off or unavailable; 0 means 100ms.
+	PollInterval time.Duration
+	// Notify waits for file system change notifications (inotify on Linux,
+	// change notifications on Windows) instead of polling where supported.
+	// Windows often does not report appends to a file the writer keeps
+	// open, as Dota does, so there new lines may take up to a second;
+	// see NotifyByDefault.
+	Notify bool
+}
+
+// follower reads the lines appended to a file like tail -F: a truncated
+// file is read again from the start, and a renamed or recreated one is
+// reopened. The file present when following starts is read from its end,
+// one reopened after a read error from where reading stopped, and files
+// that show up later from the start.
+type follower struct {
+	path string
+	opts FollowOptions
+
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte
+	// skipping is set while the rest of an oversized line is discarded.
+	skipping bool
+	buf      []byte
+
+	notifier changeNotifier
+	backoff  time.Duration
+
+	// onStatus reports connects, truncation, missing files and errors for
+	// the status line.
+	onStatus func(string)
+}
+
+func newFollower(path string, opts FollowOptions, onStatus func(string)) *follower {
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultPollInterval
+	}
+	return &follower{
+		path:     path,
+		opts:     opts,
+		buf:      make([]byte, 32*1024),
+		onStatus: onStatus,
+	}
+}
+
+// run calls onLine for every complete line until ctx is done.
+func (f *follower) run(ctx context.Context, onLine func(string)) error {
+	defer func() {
+		f.close()
+		if f.notifier != nil {
+			f.notifier.Close()
+		}
+	}()
+
+	fromEnd := true
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if f.file == nil {
+			err := f.open(fromEnd)
+			fromEnd = false
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					f.report("console.log not found (launch Dota with -condebug)")
+				} else {
+					f.report("console.log: " + err.Error())
+				}
+				if err := f.retry(ctx); err != nil {
+					return err
+				}
+				continue
+			}
+			f.report("Connected to: " + f.path)
+		}
+
+		n, err := f.readLines(onLine)
+		if err != nil {
+			f.report("console.log: " + err.Error())
+			f.close()
+			if err := f.retry(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+		f.backoff = 0
+		if n > 0 {
+			continue
+		}
+
+		// Nothing left to read: see whether the file went away under us.
+		if f.checkReplaced(onLine) {
+			continue
+		}
+		if err := f.wait(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *follower) open(fromEnd bool) error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	whence, offset := io.SeekStart, int64(0)
+	switch {
+	case fromEnd:
+		whence = io.SeekEnd
+	case f.info != nil && os.SameFile(info, f.info) && info.Size() >= f.offset:
+		offset = f.offset
+	default:
+		f.resetLine()
+	}
+	if offset, err = file.Seek(offset, whence); err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file, f.info, f.offset = file, info, offset
+	if f.opts.Notify && f.notifier == nil {
+		// Without notifications the follower simply keeps polling.
+		f.notifier, _ = newChangeNotifier(filepath.Dir(f.path))
+	}
+	return nil
+}
+
+func (f *follower) close() {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+}
+
+// readLines reads what is available and calls onLine for every complete
+// line. It returns the number of bytes read; io.EOF is not an error.
+func (f *follower) readLines(onLine func(string)) (int, error) {
+	total := 0
+	for {
+		n, err := f.file.Read(f.buf)
+		if n > 0 {
+			total += n
+			f.offset += int64(n)
+			f.split(f.buf[:n], onLine)
+		}
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+		if n < len(f.buf) {
+			return total, nil
+		}
+	}
+}
+
+func (f *follower) split(data []byte, onLine func(string)) {
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			if !f.skipping && len(f.partial)+len(data) > maxPartialLine {
+				f.skipping = true
+				f.partial = f.partial[:0]
+			}
+			if !f.skipping {
+				f.partial = append(f.partial, data...)
+			}
+			return
+		}
+		line := data[:i]
+		data = data[i+1:]
+		if f.skipping || len(f.partial)+len(line) > maxPartialLine {
+			f.resetLine()
+			continue
+		}
+		if len(f.partial) > 0 {
+			line = append(f.partial, line...)
+			f.partial = f.partial[:0]
+		}
+		onLine(string(line))
+	}
+}
+
+// resetLine forgets the unterminated line read so far.
+func (f *follower) resetLine() {
+	f.partial = f.partial[:0]
+	f.skipping = false
+}
+
+// checkReplaced handles truncation, renames and deletion of the followed
+// file once everything written to the open handle has been read. It
+// reports whether there may be new data to read right away.
+func (f *follower) checkReplaced(onLine func(string)) bool {
+	info, err := os.Stat(f.path)
+	switch {
+	case err != nil:
+		// Renamed or deleted: finish the old file and reopen (from the
+		// start) once the path is back. Its identity is dropped since a
+		// recreated file may get the same inode.
+		f.readLines(onLine)
+		f.close()
+		f.info = nil
+		return false
+	case !os.SameFile(info, f.info):
+		f.readLines(onLine)
+		f.close()
+		return true
+	case info.Size() < f.offset:
+		// Truncated in place, as Dota does with -condebug on launch. A file
+		// that already grew past the old offset again cannot be told apart
+		// from one that was only appended to.
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			f.close()
+			return false
+		}
+		f.offset = 0
+		f.resetLine()
+		f.report("console.log truncated, reading from the start")
+		return true
+	}
+	return false
+}
+
+func (f *follower) wait(ctx context.Context) error {
+	if f.notifier == nil {
+		return sleepCtx(ctx, f.opts.PollInterval)
+	}
+	if err := f.notifier.Wait(notifyTimeout); err != nil {
+		f.notifier.Close()
+		f.notifier = nil
+		return sleepCtx(ctx, f.opts.PollInterval)
+	}
+	return ctx.Err()
+}
+
+// retry waits before the next open after a failure, doubling the wait up
+// to maxBackoff while failures continue.
+func (f *follower) retry(ctx context.Context) error {
+	switch {
+	case f.backoff == 0:
+		f.backoff = minBackoff
+	case f.backoff < maxBackoff:
+		f.backoff = min(2*f.backoff, maxBackoff)
+	}
+	return sleepCtx(ctx, f.backoff)
+}
+
+func (f *follower) report(msg string) {
+	if f.onStatus != nil {
+		f.onStatus(msg)
+	}
+}
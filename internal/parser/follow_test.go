@@ -0,0 +1,203 @@
+package parser
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	lineTimeout = 3 * time.Second
+	quietPeriod = 150 * time.Millisecond
+)
+
+var followModes = []struct {
+	name string
+	opts FollowOptions
+}{
+	{"poll", FollowOptions{PollInterval: 10 * time.Millisecond}},
+	{"notify", FollowOptions{PollInterval: 10 * time.Millisecond, Notify: true}},
+}
+
+// startFollower follows path in the background until the test ends. If
+// path exists it waits for the follower to open it, so that appends made
+// afterwards are not skipped as old content.
+func startFollower(t *testing.T, path string, opts FollowOptions) <-chan string {
+	t.Helper()
+	_, statErr := os.Stat(path)
+
+	lines := make(chan string, 100)
+	connected := make(chan struct{}, 1)
+	f := newFollower(path, opts, func(msg string) {
+		if strings.HasPrefix(msg, "Connected to:") {
+			select {
+			case connected <- struct{}{}:
+			default:
+			}
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.run(ctx, func(line string) { lines <- line })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	if statErr == nil {
+		select {
+		case <-connected:
+		case <-time.After(lineTimeout):
+			t.Fatal("follower did not open the file")
+		}
+	}
+	return lines
+}
+
+// expectLines fails unless exactly want arrives on lines next.
+func expectLines(t *testing.T, lines <-chan string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-lines:
+			if got != w {
+				t.Fatalf("got line %q, want %q", got, w)
+			}
+		case <-time.After(lineTimeout):
+			t.Fatalf("timed out waiting for line %q", w)
+		}
+	}
+	select {
+	case got := <-lines:
+		t.Fatalf("unexpected line %q", got)
+	case <-time.After(quietPeriod):
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollowerAppend(t *testing.T) {
+	for _, mode := range followModes {
+		t.Run(mode.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "console.log")
+			appendFile(t, path, "old 1\nold 2\n")
+			lines := startFollower(t, path, mode.opts)
+
+			appendFile(t, path, "first\nsecond\n")
+			expectLines(t, lines, "first", "second")
+
+			// A line is only passed on once its newline is written.
+			appendFile(t, path, "par")
+			expectLines(t, lines)
+			appendFile(t, path, "tial\n")
+			expectLines(t, lines, "partial")
+		})
+	}
+}
+
+func TestFollowerTruncateInPlace(t *testing.T) {
+	for _, mode := range followModes {
+		t.Run(mode.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "console.log")
+			appendFile(t, path, "")
+			lines := startFollower(t, path, mode.opts)
+
+			appendFile(t, path, "before truncation\n")
+			expectLines(t, lines, "before truncation")
+
+			if err := os.WriteFile(path, []byte("after\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			expectLines(t, lines, "after")
+
+			appendFile(t, path, "appended\n")
+			expectLines(t, lines, "appended")
+		})
+	}
+}
+
+func TestFollowerRenameAndRecreate(t *testing.T) {
+	for _, mode := range followModes {
+		t.Run(mode.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "console.log")
+			appendFile(t, path, "")
+			lines := startFollower(t, path, mode.opts)
+
+			appendFile(t, path, "old file\n")
+			expectLines(t, lines, "old file")
+
+			// The tail is either read as usual or drained from the renamed
+			// file before the new one is opened.
+			appendFile(t, path, "old file tail\n")
+			if err := os.Rename(path, path+".1"); err != nil {
+				t.Skipf("cannot rename a file that is open here: %v", err)
+			}
+			expectLines(t, lines, "old file tail")
+
+			appendFile(t, path, "new file 1\nnew file 2\n")
+			expectLines(t, lines, "new file 1", "new file 2")
+
+			appendFile(t, path+".1", "not followed\n")
+			appendFile(t, path, "new file 3\n")
+			expectLines(t, lines, "new file 3")
+		})
+	}
+}
+
+func TestFollowerDeleteAndRecreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	appendFile(t, path, "")
+	lines := startFollower(t, path, followModes[0].opts)
+
+	appendFile(t, path, "old\n")
+	expectLines(t, lines, "old")
+
+	if err := os.Remove(path); err != nil {
+		t.Skipf("cannot delete a file that is open here: %v", err)
+	}
+	expectLines(t, lines)
+	// Same length as before, so a recycled inode would look unchanged.
+	appendFile(t, path, "new\n")
+	expectLines(t, lines, "new")
+}
+
+func TestFollowerFileAppearsLater(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	lines := startFollower(t, path, followModes[0].opts)
+
+	expectLines(t, lines)
+	appendFile(t, path, "from the start\n")
+	expectLines(t, lines, "from the start")
+}
+
+func TestSplitDropsOversizedLine(t *testing.T) {
+	f := newFollower("", FollowOptions{}, nil)
+	var got []string
+	onLine := func(line string) { got = append(got, line) }
+
+	f.split([]byte("short\n"+strings.Repeat("x", maxPartialLine)), onLine)
+	f.split([]byte("yyy tail of the long line\nnext\n"), onLine)
+	f.split([]byte(strings.Repeat("z", maxPartialLine+1)+"\nlast\n"), onLine)
+
+	want := []string{"short", "next", "last"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,21 @@
+package parser
+
+import (
+	"errors"
+	"time"
+)
+
+// NotifyByDefault says whether Start should wait for change notifications
+// unless told otherwise. Only inotify reports appends reliably enough; on
+// Windows polling stays the default.
+const NotifyByDefault = notifyReliable
+
+var errNotifyUnsupported = errors.New("change notifications not supported on this platform")
+
+// changeNotifier wakes the follower when something in the directory of
+// the followed file changes, so new lines show up without polling.
+type changeNotifier interface {
+	// Wait blocks until the directory changed or timeout passed.
+	Wait(timeout time.Duration) error
+	Close() error
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const notifyReliable = true
+
+// inotifyNotifier watches a directory with inotify.
+type inotifyNotifier struct {
+	fd  int
+	buf []byte
+}
+
+func newChangeNotifier(dir string) (changeNotifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_NONBLOCK | unix.IN_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	mask := uint32(unix.IN_MODIFY | unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_CLOSE_WRITE)
+	if _, err := unix.InotifyAddWatch(fd, dir, mask); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return &inotifyNotifier{fd: fd, buf: make([]byte, 4096)}, nil
+}
+
+func (n *inotifyNotifier) Wait(timeout time.Duration) error {
+	fds := []unix.PollFd{{Fd: int32(n.fd), Events: unix.POLLIN}}
+	ready, err := unix.Poll(fds, int(timeout/time.Millisecond))
+	if err == unix.EINTR {
+		return nil
+	}
+	if err != nil || ready == 0 {
+		return err
+	}
+	// The events themselves do not matter, only that something happened.
+	for {
+		if _, err := unix.Read(n.fd, n.buf); err != nil {
+			if err == unix.EAGAIN {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func (n *inotifyNotifier) Close() error {
+	return unix.Close(n.fd)
+}
@@ -0,0 +1,9 @@
+//go:build !windows && !linux
+
+package parser
+
+const notifyReliable = false
+
+func newChangeNotifier(dir string) (changeNotifier, error) {
+	return nil, errNotifyUnsupported
+}
@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const notifyReliable = false
+
+// dirNotifier watches a directory with FindFirstChangeNotification.
+type dirNotifier struct {
+	handle windows.Handle
+}
+
+func newChangeNotifier(dir string) (changeNotifier, error) {
+	filter := uint32(windows.FILE_NOTIFY_CHANGE_FILE_NAME | windows.FILE_NOTIFY_CHANGE_SIZE | windows.FILE_NOTIFY_CHANGE_LAST_WRITE)
+	handle, err := windows.FindFirstChangeNotification(dir, false, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &dirNotifier{handle: handle}, nil
+}
+
+func (n *dirNotifier) Wait(timeout time.Duration) error {
+	event, err := windows.WaitForSingleObject(n.handle, uint32(timeout/time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if event == windows.WAIT_OBJECT_0 {
+		return windows.FindNextChangeNotification(n.handle)
+	}
+	return nil
+}
+
+func (n *dirNotifier) Close() error {
+	return windows.FindCloseChangeNotification(n.handle)
+}
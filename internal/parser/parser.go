@@ -1,9 +1,7 @@
 package parser
 
 import (
-	"bufio"
-	"fmt"
-	"io"
+	"context"
 	"os"
 	"regexp"
 	"strings"
@@ -17,68 +15,62 @@ var overlayPatterns = []*regexp.Regexp{
 	regexp.MustCompile(`PR:SetSelectedHero\s+\d+:\[U:1:\d+\]\s+npc_dota_hero_([a-z_]+)\(\d+\)`),
 }
 
-func Start(s *state.GameState, path string, onNewHero func(heroID int)) {
+var gameRulesPattern = regexp.MustCompile(`Gamerules: entering state '(DOTA_GAMERULES_STATE_[A-Z_]+)'`)
+
+// Start follows console.log at path and applies every new line to s. It
+// keeps going across Dota restarts, which truncate or recreate the file.
+func Start(s *state.GameState, path string, opts FollowOptions, onNewHero func(heroID int)) {
 	logFile, _ := os.OpenFile("log_dota.txt", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
 	if logFile != nil {
 		defer logFile.Close()
 	}
 
-	for {
-		file, err := os.Open(path)
-		if err != nil {
-			s.SetStatus("Log file not found...")
-			time.Sleep(2 * time.Second)
-			continue
+	f := newFollower(path, opts, s.SetStatus)
+	f.run(context.Background(), func(line string) {
+		cleanLine := strings.TrimSpace(line)
+		if cleanLine == "" {
+			return
 		}
 
-		file.Seek(0, io.SeekEnd)
-		reader := bufio.NewReader(file)
-
-		fmt.Println("Connected to:", path)
-
-		for {
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				if err == io.EOF {
-					time.Sleep(100 * time.Millisecond)
-					continue
-				}
-				break
-			}
+		if logFile != nil {
+			logFile.WriteString(cleanLine + "\n")
+		}
 
-			cleanLine := strings.TrimSpace(line)
-			if cleanLine == "" {
-				continue
-			}
+		handleLine(s, cleanLine, onNewHero)
+	})
+}
 
-			if logFile != nil {
-				logFile.WriteString(cleanLine + "\n")
-			}
+// handleLine applies one trimmed console.log line to the game state. Live
+// tailing and Replay share it so both detect exactly the same things.
+func handleLine(s *state.GameState, line string, onNewHero func(heroID int)) {
+	if m := gameRulesPattern.FindStringSubmatch(line); m != nil {
+		if s.ObserveMatch("", m[1], time.Now()) {
+			s.SetStatus("New match")
+		}
+		return
+	}
 
-			matched := false
-			var heroInternal string
-			for _, re := range overlayPatterns {
-				m := re.FindStringSubmatch(cleanLine)
-				if m != nil {
-					matched = true
-					if len(m) >= 2 {
-						heroInternal = m[1]
-						s.SetStatus("Detected: " + heroInternal)
-					}
-					break
-				}
+	matched := false
+	var heroInternal string
+	for _, re := range overlayPatterns {
+		m := re.FindStringSubmatch(line)
+		if m != nil {
+			matched = true
+			if len(m) >= 2 {
+				heroInternal = m[1]
+				s.SetStatus("Detected: " + heroInternal)
 			}
+			break
+		}
+	}
 
-			if matched {
-				s.AppendOverlayLog(cleanLine, 10)
-				if heroInternal != "" {
-					added, heroID := s.AddEnemyHeroByInternalName(heroInternal)
-					if added && onNewHero != nil {
-						onNewHero(heroID)
-					}
-				}
+	if matched {
+		s.AppendOverlayLog(line, 10)
+		if heroInternal != "" {
+			added, heroID := s.AddEnemyHeroByInternalName(heroInternal)
+			if added && onNewHero != nil {
+				onNewHero(heroID)
 			}
 		}
-		file.Close()
 	}
 }
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"overlay/internal/state"
+)
+
+// consoleStampLayout is the "MM/DD HH:MM:SS" prefix of every console.log line.
+const consoleStampLayout = "01/02 15:04:05"
+
+// Replay feeds a recorded console log (e.g. log_dota.txt) through the same
+// detection as Start, from the first line instead of the end. Lines are paced
+// by their timestamps divided by speed; speed <= 0 replays without waiting.
+func Replay(ctx context.Context, s *state.GameState, path string, speed float64, onNewHero func(heroID int)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	s.SetStatus("Replaying " + path)
+
+	var last time.Time
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		cleanLine := strings.TrimSpace(scanner.Text())
+		if cleanLine == "" {
+			continue
+		}
+
+		if at, ok := consoleStamp(cleanLine); ok {
+			if !last.IsZero() && speed > 0 {
+				if gap := at.Sub(last); gap > 0 {
+					if err := sleepCtx(ctx, time.Duration(float64(gap)/speed)); err != nil {
+						return err
+					}
+				}
+			}
+			last = at
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		handleLine(s, cleanLine, onNewHero)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	s.SetStatus("Replay finished")
+	return nil
+}
+
+func consoleStamp(line string) (time.Time, bool) {
+	if len(line) < len(consoleStampLayout) {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(consoleStampLayout, line[:len(consoleStampLayout)])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DotaLaunchOptions reads Dota's Steam launch options from the most recently
+// written localconfig.vdf, i.e. the Steam user who played last. A user
+// without launch options gets "".
+func DotaLaunchOptions() (string, error) {
+	var (
+		newest   string
+		newestAt time.Time
+	)
+	for _, root := range steamRoots() {
+		matches, _ := filepath.Glob(filepath.Join(root, "userdata", "*", "config", "localconfig.vdf"))
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err == nil && info.ModTime().After(newestAt) {
+				newest, newestAt = m, info.ModTime()
+			}
+		}
+	}
+	if newest == "" {
+		return "", os.ErrNotExist
+	}
+
+	doc, err := readVDF(newest)
+	if err != nil {
+		return "", err
+	}
+	app := doc.Child("UserLocalConfigStore").
+		Child("Software").
+		Child("Valve").
+		Child("Steam").
+		Child("apps").
+		Child(dotaAppID)
+	opts, _ := app.Get("LaunchOptions")
+	return opts, nil
+}
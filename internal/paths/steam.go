@@ -1,43 +1,147 @@
 package paths
 
 import (
-	"bufio"
 	"os"
 	"path/filepath"
-	"strings"
 
-	"golang.org/x/sys/windows/registry"
+	"overlay/internal/vdf"
 )
 
+// dotaAppID is Dota 2's Steam app ID.
+const dotaAppID = "570"
+
+// FindDotaLogPath looks for Dota's console.log in every Steam library that
+// has Dota installed, across every Steam install found on this machine.
+// Where Steam lives is up to the platform; see steamRoots.
 func FindDotaLogPath() (string, error) {
-	var steamPath string
-	k, err := registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Valve\Steam`, registry.QUERY_VALUE)
+	for _, dir := range dotaInstallDirs(steamRoots()) {
+		path := filepath.Join(dir, "game", "dota", "console.log")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// DotaConsoleLogPath is where Dota writes console.log with -condebug: an
+// existing console.log if there is one, otherwise the path in the first
+// Dota install, so that it can be followed before Dota's first launch.
+func DotaConsoleLogPath() (string, error) {
+	if path, err := FindDotaLogPath(); err == nil {
+		return path, nil
+	}
+	dir, err := FindDotaDir()
 	if err != nil {
-		k, err = registry.OpenKey(registry.LOCAL_MACHINE, `SOFTWARE\Valve\Steam`, registry.QUERY_VALUE)
+		return "", err
 	}
-	if err == nil {
-		steamPath, _, _ = k.GetStringValue("InstallPath")
-		k.Close()
+	return filepath.Join(dir, "game", "dota", "console.log"), nil
+}
+
+// FindDotaDir returns the Dota install folder (".../common/dota 2 beta") of
+// the first Steam library that has one.
+func FindDotaDir() (string, error) {
+	for _, dir := range dotaInstallDirs(steamRoots()) {
+		if _, err := os.Stat(filepath.Join(dir, "game", "dota")); err == nil {
+			return dir, nil
+		}
+	}
+	return "", os.ErrNotExist
+}
+
+// dotaInstallDirs returns the Dota install folder of each library that has
+// an appmanifest_570.acf, in the order Steam lists the libraries.
+func dotaInstallDirs(roots []string) []string {
+	var dirs []string
+	for _, lib := range steamLibraries(roots) {
+		manifest, err := readVDF(filepath.Join(lib, "steamapps", "appmanifest_"+dotaAppID+".acf"))
+		if err != nil {
+			continue
+		}
+		installDir, ok := manifest.Child("AppState").Get("installdir")
+		if !ok || installDir == "" {
+			installDir = "dota 2 beta"
+		}
+		dirs = append(dirs, filepath.Join(lib, "steamapps", "common", installDir))
 	}
+	return dirs
+}
 
-	libs := []string{filepath.Join(steamPath, "steamapps")}
-	vdf := filepath.Join(steamPath, "steamapps", "libraryfolders.vdf")
-	if f, err := os.Open(vdf); err == nil {
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			if strings.Contains(scanner.Text(), `"path"`) {
-				p := strings.Split(scanner.Text(), "\"")[3]
-				libs = append(libs, filepath.Join(p, "steamapps"))
+// steamLibraries lists each root plus every library its libraryfolders.vdf
+// points at, without duplicates. Libraries whose "apps" list lacks Dota are
+// left out; older files without that list are kept.
+func steamLibraries(roots []string) []string {
+	var libs []string
+	seen := make(map[string]bool)
+	add := func(lib string) {
+		key := lib
+		if resolved, err := filepath.EvalSymlinks(lib); err == nil {
+			key = resolved
+		}
+		if !seen[key] {
+			seen[key] = true
+			libs = append(libs, lib)
+		}
+	}
+
+	for _, root := range roots {
+		add(root)
+
+		folders, err := readVDF(filepath.Join(root, "steamapps", "libraryfolders.vdf"))
+		if err != nil {
+			continue
+		}
+		list := folders.Child("libraryfolders")
+		if list == nil {
+			continue
+		}
+		for _, entry := range list.Pairs {
+			// Old files: "1" "D:\\SteamLibrary"; current ones: "1" { "path" ... }.
+			if entry.Object == nil {
+				if entry.Value != "" && isDigits(entry.Key) {
+					add(entry.Value)
+				}
+				continue
 			}
+			path, ok := entry.Object.Get("path")
+			if !ok || path == "" {
+				continue
+			}
+			if apps := entry.Object.Child("apps"); apps != nil && !apps.Has(dotaAppID) {
+				continue
+			}
+			add(path)
 		}
-		f.Close()
 	}
+	return libs
+}
 
-	for _, lib := range libs {
-		path := filepath.Join(lib, "common", "dota 2 beta", "game", "dota", "console.log")
-		if _, err := os.Stat(path); err == nil {
-			return path, nil
+func readVDF(path string) (*vdf.Object, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return vdf.Parse(f)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
 		}
 	}
-	return "", os.ErrNotExist
+	return s != ""
+}
+
+// homeDirs joins each rel onto the user's home directory.
+func homeDirs(rel ...string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	out := make([]string, 0, len(rel))
+	for _, r := range rel {
+		out = append(out, filepath.Join(home, r))
+	}
+	return out
 }
@@ -0,0 +1,5 @@
+package paths
+
+func steamRoots() []string {
+	return homeDirs("Library/Application Support/Steam")
+}
@@ -0,0 +1,13 @@
+package paths
+
+// steamRoots covers the native package (~/.steam/steam is usually a symlink
+// to ~/.local/share/Steam) and the Flatpak one.
+func steamRoots() []string {
+	return homeDirs(
+		".steam/steam",
+		".local/share/Steam",
+		".steam/root",
+		".var/app/com.valvesoftware.Steam/.local/share/Steam",
+		".var/app/com.valvesoftware.Steam/.steam/steam",
+	)
+}
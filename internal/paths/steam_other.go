@@ -0,0 +1,7 @@
+//go:build !windows && !linux && !darwin
+
+package paths
+
+func steamRoots() []string {
+	return nil
+}
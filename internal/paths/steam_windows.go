@@ -0,0 +1,27 @@
+package paths
+
+import "golang.org/x/sys/windows/registry"
+
+// steamRoots reads the Steam install path from the registry.
+func steamRoots() []string {
+	var roots []string
+	for _, key := range []struct {
+		root  registry.Key
+		path  string
+		value string
+	}{
+		{registry.LOCAL_MACHINE, `SOFTWARE\WOW6432Node\Valve\Steam`, "InstallPath"},
+		{registry.LOCAL_MACHINE, `SOFTWARE\Valve\Steam`, "InstallPath"},
+		{registry.CURRENT_USER, `SOFTWARE\Valve\Steam`, "SteamPath"},
+	} {
+		k, err := registry.OpenKey(key.root, key.path, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		if p, _, err := k.GetStringValue(key.value); err == nil && p != "" {
+			roots = append(roots, p)
+		}
+		k.Close()
+	}
+	return roots
+}
@@ -0,0 +1,147 @@
+// Package setup checks that Dota is set up to feed the overlay: launch
+// options, console.log, the GSI cfg and the GSI port.
+package setup
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"overlay/internal/gsi"
+	"overlay/internal/paths"
+)
+
+// Check is one line of the checklist. Fix says what to do when !OK.
+type Check struct {
+	Name   string
+	OK     bool
+	Detail string
+	Fix    string
+}
+
+// Run performs every check. opts describes the GSI server the cfg should
+// point at.
+func Run(opts gsi.CfgOptions) []Check {
+	var checks []Check
+
+	dotaDir, err := paths.FindDotaDir()
+	if err != nil {
+		checks = append(checks, Check{
+			Name:   "Dota install",
+			Detail: "not found in any Steam library",
+			Fix:    "install Dota 2, or pass the folder to gsicfg -dota",
+		})
+	} else {
+		checks = append(checks, Check{Name: "Dota install", OK: true, Detail: dotaDir})
+	}
+
+	checks = append(checks, launchOptionChecks()...)
+
+	if dotaDir != "" {
+		checks = append(checks, consoleLogCheck(dotaDir), cfgCheck(dotaDir, opts))
+	}
+	checks = append(checks, portCheck(opts.Addr))
+	return checks
+}
+
+// Failed is how many checks did not pass.
+func Failed(checks []Check) int {
+	n := 0
+	for _, c := range checks {
+		if !c.OK {
+			n++
+		}
+	}
+	return n
+}
+
+func launchOptionChecks() []Check {
+	launch, err := paths.DotaLaunchOptions()
+	if err != nil {
+		return []Check{{
+			Name:   "Launch options",
+			Detail: "Steam localconfig.vdf not readable: " + err.Error(),
+			Fix:    "set -condebug -gamestateintegration in Dota's launch options",
+		}}
+	}
+
+	var checks []Check
+	for _, opt := range []struct{ flag, why string }{
+		{"-condebug", "writes console.log for hero detection"},
+		{"-gamestateintegration", "enables GSI"},
+	} {
+		c := Check{Name: opt.flag, OK: hasFlag(launch, opt.flag), Detail: opt.why}
+		if !c.OK {
+			c.Fix = "add " + opt.flag + " to Dota's launch options in Steam"
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
+
+func hasFlag(launch, flag string) bool {
+	for _, f := range strings.Fields(launch) {
+		if strings.EqualFold(f, flag) {
+			return true
+		}
+	}
+	return false
+}
+
+func consoleLogCheck(dotaDir string) Check {
+	path := filepath.Join(dotaDir, "game", "dota", "console.log")
+	if _, err := os.Stat(path); err != nil {
+		return Check{
+			Name:   "console.log",
+			Detail: "missing",
+			Fix:    "launch Dota once with -condebug",
+		}
+	}
+	return Check{Name: "console.log", OK: true, Detail: path}
+}
+
+func cfgCheck(dotaDir string, opts gsi.CfgOptions) Check {
+	path := filepath.Join(gsi.CfgDir(dotaDir), gsi.CfgFileName)
+	diffs, err := gsi.CheckCfg(path, opts)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return Check{Name: "GSI cfg", Detail: "not installed", Fix: "run gsicfg, then restart Dota"}
+	case err != nil:
+		return Check{Name: "GSI cfg", Detail: err.Error(), Fix: "run gsicfg, then restart Dota"}
+	case len(diffs) > 0:
+		return Check{Name: "GSI cfg", Detail: "out of date: " + diffs[0], Fix: "run gsicfg, then restart Dota"}
+	}
+	return Check{Name: "GSI cfg", OK: true, Detail: path}
+}
+
+// portCheck passes when addr is free or already served by an overlay.
+func portCheck(addr string) Check {
+	c := Check{Name: "GSI port " + addr}
+
+	if ln, err := net.Listen("tcp", addr); err == nil {
+		ln.Close()
+		c.OK = true
+		c.Detail = "free"
+		return c
+	}
+
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get(gsi.ServerURL(addr))
+	if err == nil {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 16))
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK && strings.TrimSpace(string(body)) == "ok" {
+			c.OK = true
+			c.Detail = "served by the overlay"
+			return c
+		}
+	}
+	c.Detail = "taken by another program"
+	c.Fix = "free the port or change gsi_addr in config.json and rerun gsicfg"
+	return c
+}
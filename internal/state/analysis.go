@@ -0,0 +1,42 @@
+package state
+
+// BeginAnalysis starts a Best Picks analysis and returns its generation.
+// Only the newest generation may commit, so a slow analysis that finishes
+// after a newer one cannot overwrite it.
+func (s *GameState) BeginAnalysis() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.analysisGen++
+	return s.analysisGen
+}
+
+// CommitHeroCounters stores heroID's COUNTERS table if the match is still
+// the one with matchGen (see MatchGen) and reports whether it did, so a
+// lookup that outlives its match cannot leak into the next one.
+func (s *GameState) CommitHeroCounters(matchGen uint64, heroID int, picks []CounterPick) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if matchGen != s.matchGen {
+		return false
+	}
+	if s.counterPicksBy == nil {
+		s.counterPicksBy = make(map[int][]CounterPick)
+	}
+	s.lastCounterHero = heroID
+	s.counterPicksBy[heroID] = append([]CounterPick(nil), picks...)
+	s.notifyLocked()
+	return true
+}
+
+// CommitBestCounters stores counters if gen is still the latest analysis and
+// reports whether it did.
+func (s *GameState) CommitBestCounters(gen uint64, counters []ScoredHero) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.analysisGen {
+		return false
+	}
+	s.bestCounters = append([]ScoredHero(nil), counters...)
+	s.notifyLocked()
+	return true
+}
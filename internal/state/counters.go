@@ -1,5 +1,20 @@
 package state
 
-func (s *GameState) CalculateCounters() {
-	// TODO: implement when log structure is defined
+import "overlay/internal/opendota"
+
+// CalculateCounters ranks the heroes that do best against the hero whose
+// matchups these are, as CounterPick rows for the COUNTERS table.
+func CalculateCounters(matchups []opendota.HeroMatchup, scorer opendota.Scorer, base opendota.BaseRates, minGames int, limit int) []CounterPick {
+	counters := opendota.CalculateCounters(matchups, scorer, base, minGames, limit)
+	picks := make([]CounterPick, 0, len(counters))
+	for _, c := range counters {
+		picks = append(picks, CounterPick{
+			HeroID:     c.HeroID,
+			Games:      c.Games,
+			WinRate:    c.WinRate,
+			Score:      c.Score,
+			Confidence: c.Confidence,
+		})
+	}
+	return picks
 }
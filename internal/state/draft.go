@@ -0,0 +1,120 @@
+package state
+
+import "time"
+
+// DraftEntry is one pick or ban in the order it happened. At and ClockTime
+// record when we first saw it, since GSI only sends the list itself.
+type DraftEntry struct {
+	Order     int       `json:"order"`
+	HeroID    int       `json:"hero_id"`
+	IsPick    bool      `json:"is_pick"`
+	Team      string    `json:"team"`
+	At        time.Time `json:"at"`
+	ClockTime int       `json:"clock_time"`
+}
+
+// RecordDraft merges the full picks/bans list from a GSI tick. Entries already
+// recorded keep their timing; if the list diverges from what we have, the
+// tail from the first difference is replaced, and entries past the end of a
+// shorter list (a restarted draft) are dropped.
+func (s *GameState) RecordDraft(entries []DraftEntry, at time.Time, clockTime int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := false
+	for i, e := range entries {
+		if i < len(s.draft) {
+			have := s.draft[i]
+			if have.HeroID == e.HeroID && have.IsPick == e.IsPick && have.Team == e.Team {
+				continue
+			}
+			s.draft = s.draft[:i]
+		}
+		e.Order = i + 1
+		e.At = at
+		e.ClockTime = clockTime
+		s.draft = append(s.draft, e)
+		changed = true
+	}
+	if len(entries) < len(s.draft) {
+		s.draft = s.draft[:len(entries)]
+		changed = true
+	}
+	if changed {
+		s.notifyLocked()
+	}
+}
+
+// SetDraftTurn records whose draft turn it is and whether it is a ban, and
+// reports whether bans just opened or closed. team is "" outside a draft.
+func (s *GameState) SetDraftTurn(team string, banning bool) bool {
+	banning = banning && team != ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draftTurnTeam == team && s.draftBanning == banning {
+		return false
+	}
+	changed := s.draftBanning != banning
+	s.draftTurnTeam = team
+	s.draftBanning = banning
+	s.notifyLocked()
+	return changed
+}
+
+// DraftBanning reports whether a ban turn is open.
+func (s *GameState) DraftBanning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.draftBanning
+}
+
+// CommitBanSuggestions stores ban suggestions from the analysis gen, if it is
+// still the latest one; see BeginAnalysis.
+func (s *GameState) CommitBanSuggestions(gen uint64, bans []ScoredHero) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.analysisGen {
+		return false
+	}
+	s.banSuggestions = append([]ScoredHero(nil), bans...)
+	s.notifyLocked()
+	return true
+}
+
+func (s *GameState) Draft() []DraftEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]DraftEntry(nil), s.draft...)
+}
+
+// UnavailableHeroes is every hero that can no longer be picked by us: banned,
+// picked by either side, or already seen in the match.
+func (s *GameState) UnavailableHeroes() map[int]struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return unavailableHeroes(s.draft, s.allyHeroesIDs, s.enemyHeroesIDs)
+}
+
+// IsUnavailable reports whether heroID was banned or picked as of the snapshot.
+func (snap Snapshot) IsUnavailable(heroID int) bool {
+	for _, e := range snap.Draft {
+		if e.HeroID == heroID {
+			return true
+		}
+	}
+	return containsInt(snap.AllyHeroesIDs, heroID) || containsInt(snap.EnemyHeroesIDs, heroID)
+}
+
+func unavailableHeroes(draft []DraftEntry, lists ...[]int) map[int]struct{} {
+	out := make(map[int]struct{}, len(draft))
+	for _, e := range draft {
+		out[e.HeroID] = struct{}{}
+	}
+	for _, list := range lists {
+		for _, id := range list {
+			out[id] = struct{}{}
+		}
+	}
+	return out
+}
@@ -0,0 +1,244 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"overlay/internal/opendota"
+)
+
+const (
+	engineRequestTimeout = 10 * time.Second
+	engineHeroTimeout    = 20 * time.Second
+
+	// Heroes need this many games in a matchup to be scored at all.
+	engineMinGames = 10
+	// counterMinGames and counterRows shape one enemy's COUNTERS table; a
+	// few spare rows cover heroes banned later on.
+	counterMinGames = 20
+	counterRows     = 15
+	bestRows        = 10
+)
+
+// EngineConfig is what a CounterEngine needs besides the state. Synergy and
+// BaseRates may be nil; Position and PoolMode may be left empty. Without
+// base rates every hero is scored against an even 50%.
+type EngineConfig struct {
+	Matchups  opendota.MatchupSource
+	BaseRates opendota.BaseRateSource
+	Synergy   opendota.SynergySource
+	Scorer    opendota.Scorer
+	Weights   opendota.Weights
+	Position  opendota.Position
+	PoolMode  string
+	// Workers bounds concurrent lookups per analysis.
+	Workers int
+}
+
+// CounterEngine turns the enemies it is told about and the rest of a
+// GameState into the COUNTERS tables, Best Picks and ban suggestions: it
+// keeps the enemy set, fetches matchups, scores them and commits the
+// results. Analyses run in the background and only the newest one is
+// committed; cancelling the engine's context stops them.
+type CounterEngine struct {
+	ctx context.Context
+	st  *GameState
+	cfg EngineConfig
+	wg  sync.WaitGroup
+
+	mu sync.Mutex
+	// enemies are in the order they were seen, for the match matchGen.
+	enemies  []int
+	matchGen uint64
+}
+
+func NewCounterEngine(ctx context.Context, st *GameState, cfg EngineConfig) *CounterEngine {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	return &CounterEngine{ctx: ctx, st: st, cfg: cfg, matchGen: st.MatchGen()}
+}
+
+// Enemies is the enemy set analyses run on: every hero passed to
+// EnemyAdded in the current match that has not turned out to be an ally.
+func (e *CounterEngine) Enemies() []int {
+	allies := e.st.AllyHeroes()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.syncMatchLocked()
+	for _, id := range allies {
+		e.enemies = removeInt(e.enemies, id)
+	}
+	return append([]int(nil), e.enemies...)
+}
+
+// syncMatchLocked forgets the enemies of a match the state has moved past.
+func (e *CounterEngine) syncMatchLocked() {
+	if gen := e.st.MatchGen(); gen != e.matchGen {
+		e.matchGen = gen
+		e.enemies = nil
+	}
+}
+
+// Wait blocks until the background work started so far has finished.
+func (e *CounterEngine) Wait() {
+	e.wg.Wait()
+}
+
+// EnemyAdded adds heroID to the enemy set, builds its COUNTERS table and
+// refreshes Best Picks. It returns right away.
+func (e *CounterEngine) EnemyAdded(heroID int) {
+	e.mu.Lock()
+	e.syncMatchLocked()
+	if !containsInt(e.enemies, heroID) {
+		e.enemies = append(e.enemies, heroID)
+	}
+	matchGen := e.matchGen
+	e.mu.Unlock()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ctx, cancel := context.WithTimeout(e.ctx, engineHeroTimeout)
+		defer cancel()
+
+		matchups, err := e.cfg.Matchups.GetHeroMatchups(ctx, heroID)
+		if e.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			e.st.SetStatus("OpenDota error: " + err.Error())
+			return
+		}
+		counters := CalculateCounters(matchups, e.cfg.Scorer, e.baseRates(), counterMinGames, counterRows)
+		if !e.st.CommitHeroCounters(matchGen, heroID, counters) {
+			// The match ended while we were fetching.
+			return
+		}
+
+		e.analyze()
+	}()
+}
+
+// Refresh re-runs the analysis in the background, e.g. after an ally pick,
+// a new hero pool or a ban turn.
+func (e *CounterEngine) Refresh() {
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.analyze()
+	}()
+}
+
+func (e *CounterEngine) analyze() {
+	st := e.st
+	gen := st.BeginAnalysis()
+	if e.ctx.Err() != nil {
+		return
+	}
+	base := e.baseRates()
+	if st.DraftBanning() {
+		st.CommitBanSuggestions(gen, e.suggestBans(base))
+	}
+
+	counters, err := opendota.AnalyzeCounters(e.Enemies(), e.fetchMatchups, e.cfg.Scorer, base, engineMinGames, e.cfg.Workers)
+	if e.ctx.Err() != nil {
+		return
+	}
+	if err != nil {
+		if len(counters) == 0 {
+			st.SetStatus("OpenDota analyze error: " + err.Error())
+			return
+		}
+		st.SetStatus("OpenDota partial results: " + err.Error())
+	}
+
+	allies := st.AllyHeroes()
+	results := opendota.CombineScores(counters, e.synergyWith(allies, base), e.cfg.Weights)
+
+	allyPositions := make([][]opendota.Position, 0, len(allies))
+	for _, id := range allies {
+		allyPositions = append(allyPositions, e.positionsOf(id))
+	}
+	covered := opendota.CoveredPositions(allyPositions)
+	results = opendota.ApplyRoles(results, e.positionsOf, e.cfg.Position, covered, e.cfg.Weights.Role)
+	results = opendota.ApplyPool(results, st.HeroPool(), e.cfg.PoolMode, e.cfg.Weights.Pool)
+
+	st.CommitBestCounters(gen, topAvailable(results, st.UnavailableHeroes(), bestRows))
+}
+
+// suggestBans scores what to ban: heroes that beat our lineup, blended with
+// the ones that work best with the enemy's picks. Before anyone has picked
+// (or without duo data) that leaves nothing, so the strongest heroes
+// overall are suggested instead.
+func (e *CounterEngine) suggestBans(base opendota.BaseRates) []ScoredHero {
+	threats, err := opendota.AnalyzeCounters(e.st.AllyHeroes(), e.fetchMatchups, e.cfg.Scorer, base, engineMinGames, e.cfg.Workers)
+	if err != nil {
+		e.st.SetStatus("Ban analysis error: " + err.Error())
+	}
+	enemyWants := e.synergyWith(e.Enemies(), base)
+	bans := opendota.CombineScores(threats, enemyWants, e.cfg.Weights)
+	if len(bans) == 0 {
+		bans = opendota.StrongestHeroes(base)
+	}
+	return topAvailable(bans, e.st.UnavailableHeroes(), bestRows)
+}
+
+func (e *CounterEngine) synergyWith(heroIDs []int, base opendota.BaseRates) []opendota.ScoredHero {
+	if e.cfg.Synergy == nil {
+		return nil
+	}
+	synergy, err := opendota.AnalyzeSynergy(heroIDs, e.fetchDuos, e.cfg.Scorer, base, engineMinGames, e.cfg.Workers)
+	if err != nil {
+		e.st.SetStatus("Synergy error: " + err.Error())
+	}
+	return synergy
+}
+
+// baseRates fetches every hero's base win rate; on failure heroes are
+// scored against an even 50%.
+func (e *CounterEngine) baseRates() opendota.BaseRates {
+	if e.cfg.BaseRates == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, engineRequestTimeout)
+	defer cancel()
+	base, err := e.cfg.BaseRates.GetBaseRates(ctx)
+	if err != nil {
+		e.st.SetStatus("Base win rates error: " + err.Error())
+	}
+	return base
+}
+
+func (e *CounterEngine) fetchMatchups(heroID int) ([]opendota.HeroMatchup, error) {
+	ctx, cancel := context.WithTimeout(e.ctx, engineRequestTimeout)
+	defer cancel()
+	return e.cfg.Matchups.GetHeroMatchups(ctx, heroID)
+}
+
+func (e *CounterEngine) fetchDuos(heroID int) ([]opendota.HeroDuo, error) {
+	ctx, cancel := context.WithTimeout(e.ctx, engineRequestTimeout)
+	defer cancel()
+	return e.cfg.Synergy.GetHeroDuos(ctx, heroID)
+}
+
+func (e *CounterEngine) positionsOf(heroID int) []opendota.Position {
+	return opendota.HeroPositions(e.st.HeroRoles(heroID))
+}
+
+// topAvailable keeps the first n results that are still pickable.
+func topAvailable(results []opendota.ScoredHero, taken map[int]struct{}, n int) []ScoredHero {
+	out := make([]ScoredHero, 0, n)
+	for _, r := range results {
+		if _, isTaken := taken[r.HeroID]; isTaken {
+			continue
+		}
+		out = append(out, ScoredHero{HeroID: r.HeroID, Score: r.Score})
+		if len(out) >= n {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,189 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"overlay/internal/opendota"
+)
+
+// fakeMatchups serves fixed matchups; unknown heroes are an error.
+type fakeMatchups map[int][]opendota.HeroMatchup
+
+func (f fakeMatchups) GetHeroMatchups(ctx context.Context, heroID int) ([]opendota.HeroMatchup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	matchups, ok := f[heroID]
+	if !ok {
+		return nil, fmt.Errorf("no matchups for hero %d", heroID)
+	}
+	return matchups, nil
+}
+
+// Against hero 1, hero 10 wins 60% of 1000 games, hero 11 45% and hero 12
+// 50%. Wins count hero 1's wins, as OpenDota does.
+var testMatchups = fakeMatchups{
+	1: {
+		{HeroID: 10, GamesPlayed: 1000, Wins: 400},
+		{HeroID: 11, GamesPlayed: 1000, Wins: 550},
+		{HeroID: 12, GamesPlayed: 1000, Wins: 500},
+	},
+}
+
+func newTestEngine(t *testing.T, ctx context.Context) (*GameState, *CounterEngine) {
+	t.Helper()
+	scorer, err := opendota.ScorerFor(opendota.ScoringRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := NewGameState(nil, nil)
+	engine := NewCounterEngine(ctx, st, EngineConfig{
+		Matchups: testMatchups,
+		Scorer:   scorer,
+		Weights:  opendota.Weights{Counter: 1},
+	})
+	return st, engine
+}
+
+func TestCounterEngineEnemyAdded(t *testing.T) {
+	st, engine := newTestEngine(t, context.Background())
+
+	st.AddEnemyHeroByID(1)
+	engine.EnemyAdded(1)
+	engine.Wait()
+
+	if got := engine.Enemies(); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("Enemies() = %v, want [1]", got)
+	}
+	snap := st.Snapshot(0)
+	counters := snap.CounterPicksBy[1]
+	if len(counters) != 3 || counters[0].HeroID != 10 || counters[2].HeroID != 11 {
+		t.Fatalf("COUNTERS for hero 1 = %+v, want 10, 12, 11", counters)
+	}
+	if counters[0].WinRate != 0.6 {
+		t.Errorf("hero 10 win rate = %v, want 0.6", counters[0].WinRate)
+	}
+	best := snap.BestCounters
+	if len(best) != 3 || best[0].HeroID != 10 {
+		t.Fatalf("Best Picks = %+v, want hero 10 first", best)
+	}
+}
+
+func TestCounterEngineDropsAllies(t *testing.T) {
+	st, engine := newTestEngine(t, context.Background())
+
+	engine.EnemyAdded(1)
+	engine.Wait()
+	st.AddAllyHeroByID(1)
+
+	if got := engine.Enemies(); len(got) != 0 {
+		t.Fatalf("Enemies() = %v after hero 1 joined the allies, want none", got)
+	}
+}
+
+func TestCounterEngineForgetsOldMatch(t *testing.T) {
+	st, engine := newTestEngine(t, context.Background())
+
+	st.ObserveMatch("100", "", time.Now())
+	engine.EnemyAdded(1)
+	engine.Wait()
+	st.ObserveMatch("200", "", time.Now())
+
+	if got := engine.Enemies(); len(got) != 0 {
+		t.Fatalf("Enemies() = %v in a new match, want none", got)
+	}
+}
+
+// slowMatchups serves testMatchups once release is closed.
+type slowMatchups struct {
+	release chan struct{}
+}
+
+func (s slowMatchups) GetHeroMatchups(ctx context.Context, heroID int) ([]opendota.HeroMatchup, error) {
+	<-s.release
+	return testMatchups.GetHeroMatchups(ctx, heroID)
+}
+
+func TestCounterEngineDropsCountersOfEndedMatch(t *testing.T) {
+	st, engine := newTestEngine(t, context.Background())
+	slow := slowMatchups{release: make(chan struct{})}
+	engine.cfg.Matchups = slow
+
+	st.ObserveMatch("100", "", time.Now())
+	engine.EnemyAdded(1)
+	st.ObserveMatch("200", "", time.Now())
+	close(slow.release)
+	engine.Wait()
+
+	snap := st.Snapshot(0)
+	if len(snap.CounterPicksBy) != 0 || snap.LastCounterHero != 0 {
+		t.Fatalf("counters of the previous match leaked into the new one: %+v", snap.CounterPicksBy)
+	}
+}
+
+func TestCounterEngineCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	st, engine := newTestEngine(t, ctx)
+
+	engine.EnemyAdded(1)
+	engine.Refresh()
+	engine.Wait()
+
+	snap := st.Snapshot(0)
+	if len(snap.CounterPicksBy[1]) != 0 || len(snap.BestCounters) != 0 {
+		t.Fatalf("cancelled engine committed results: %+v, %+v", snap.CounterPicksBy, snap.BestCounters)
+	}
+}
+
+func TestCounterEngineKeepsMatchOnReconnect(t *testing.T) {
+	st, engine := newTestEngine(t, context.Background())
+	now := time.Now()
+
+	st.ObserveMatch("100", GameStateInProgress, now)
+	st.AddEnemyHeroByID(1)
+	st.RecordDraft([]DraftEntry{{Order: 1, HeroID: 1, IsPick: true, Team: "dire"}}, now, 0)
+	engine.EnemyAdded(1)
+	engine.Wait()
+
+	// A reconnect goes through the early states again, in console.log
+	// (without a match id) and in GSI (with the same one).
+	for _, step := range []struct{ matchID, gameState string }{
+		{"", "DOTA_GAMERULES_STATE_INIT"},
+		{"100", "DOTA_GAMERULES_STATE_WAIT_FOR_PLAYERS_TO_LOAD"},
+		{"", GameStateHeroSelection},
+		{"100", GameStateInProgress},
+	} {
+		if st.ObserveMatch(step.matchID, step.gameState, now) {
+			t.Fatalf("ObserveMatch(%q, %q) started a new match on a reconnect", step.matchID, step.gameState)
+		}
+	}
+
+	if got := engine.Enemies(); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Enemies() = %v after the reconnect, want [1]", got)
+	}
+	snap := st.Snapshot(0)
+	if len(snap.CounterPicksBy[1]) == 0 || len(snap.Draft) != 1 {
+		t.Errorf("reconnect dropped COUNTERS or the draft: %+v, %+v", snap.CounterPicksBy, snap.Draft)
+	}
+	if history := st.MatchHistory(); len(history) != 0 {
+		t.Errorf("reconnect archived %d matches, want none", len(history))
+	}
+}
+
+func TestObserveMatchRegressionWithoutMatchID(t *testing.T) {
+	st := NewGameState(nil, nil)
+	now := time.Now()
+
+	st.ObserveMatch("", GameStateInProgress, now)
+	st.AddEnemyHeroByID(1)
+	if !st.ObserveMatch("", GameStateHeroSelection, now) {
+		t.Fatal("going back to hero selection without a match id did not start a new match")
+	}
+	if snap := st.Snapshot(0); len(snap.EnemyHeroesIDs) != 0 {
+		t.Errorf("enemies = %v in the new match, want none", snap.EnemyHeroesIDs)
+	}
+}
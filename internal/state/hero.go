@@ -0,0 +1,45 @@
+package state
+
+type Ability struct {
+	Name        string `json:"name"`
+	Level       int    `json:"level"`
+	CanCast     bool   `json:"can_cast"`
+	Passive     bool   `json:"passive"`
+	Cooldown    int    `json:"cooldown"`
+	MaxCooldown int    `json:"max_cooldown"`
+	Ultimate    bool   `json:"ultimate"`
+}
+
+// HeroStatus is everything GSI reports about our hero beyond the basic
+// HP/MP numbers: life cycle, buyback, disables, upgrades and talents.
+type HeroStatus struct {
+	Alive           bool   `json:"alive"`
+	RespawnSeconds  int    `json:"respawn_seconds"`
+	BuybackCost     int    `json:"buyback_cost"`
+	BuybackCooldown int    `json:"buyback_cooldown"`
+	Stunned         bool   `json:"stunned"`
+	Silenced        bool   `json:"silenced"`
+	Hexed           bool   `json:"hexed"`
+	Disarmed        bool   `json:"disarmed"`
+	Muted           bool   `json:"muted"`
+	Break           bool   `json:"break"`
+	MagicImmune     bool   `json:"magic_immune"`
+	Smoked          bool   `json:"smoked"`
+	AghanimsScepter bool   `json:"aghanims_scepter"`
+	AghanimsShard   bool   `json:"aghanims_shard"`
+	Talents         []bool `json:"talents"`
+}
+
+func (s *GameState) SetGSIHero(status HeroStatus, abilities []Ability) {
+	s.mu.Lock()
+	status.Talents = append([]bool(nil), status.Talents...)
+	s.gsiHeroStatus = status
+	s.gsiAbilities = append([]Ability(nil), abilities...)
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+func cloneHeroStatus(src HeroStatus) HeroStatus {
+	src.Talents = append([]bool(nil), src.Talents...)
+	return src
+}
@@ -0,0 +1,38 @@
+package state
+
+type Item struct {
+	Name        string `json:"name"`
+	Charges     int    `json:"charges"`
+	Cooldown    int    `json:"cooldown"`
+	MaxCooldown int    `json:"max_cooldown"`
+	CanCast     bool   `json:"can_cast"`
+	Passive     bool   `json:"passive"`
+	Purchaser   int    `json:"purchaser"`
+}
+
+// Items holds our hero's slots as reported by GSI. Empty slots are kept with
+// an empty Name so slot positions stay stable.
+type Items struct {
+	Inventory        []Item `json:"inventory"`
+	Stash            []Item `json:"stash"`
+	Teleport         Item   `json:"teleport"`
+	Neutral          []Item `json:"neutral"`
+	PreservedNeutral []Item `json:"preserved_neutral"`
+}
+
+func (s *GameState) SetGSIItems(items Items) {
+	s.mu.Lock()
+	s.gsiItems = cloneItems(items)
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+func cloneItems(src Items) Items {
+	return Items{
+		Inventory:        append([]Item(nil), src.Inventory...),
+		Stash:            append([]Item(nil), src.Stash...),
+		Teleport:         src.Teleport,
+		Neutral:          append([]Item(nil), src.Neutral...),
+		PreservedNeutral: append([]Item(nil), src.PreservedNeutral...),
+	}
+}
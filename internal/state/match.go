@@ -0,0 +1,164 @@
+package state
+
+import "time"
+
+const (
+	GameStateHeroSelection = "DOTA_GAMERULES_STATE_HERO_SELECTION"
+	GameStateStrategyTime  = "DOTA_GAMERULES_STATE_STRATEGY_TIME"
+	GameStatePreGame       = "DOTA_GAMERULES_STATE_PRE_GAME"
+	GameStateInProgress    = "DOTA_GAMERULES_STATE_GAME_IN_PROGRESS"
+	GameStatePostGame      = "DOTA_GAMERULES_STATE_POST_GAME"
+)
+
+const maxMatchHistory = 20
+
+// gameStateOrder ranks the game rules states in the order a match goes
+// through them. Unknown states rank 0 and never trigger a new match.
+var gameStateOrder = map[string]int{
+	"DOTA_GAMERULES_STATE_INIT":                     1,
+	"DOTA_GAMERULES_STATE_WAIT_FOR_PLAYERS_TO_LOAD": 2,
+	"DOTA_GAMERULES_STATE_CUSTOM_GAME_SETUP":        3,
+	GameStateHeroSelection:                          4,
+	GameStateStrategyTime:                           5,
+	"DOTA_GAMERULES_STATE_TEAM_SHOWCASE":            6,
+	"DOTA_GAMERULES_STATE_WAIT_FOR_MAP_TO_LOAD":     7,
+	GameStatePreGame:                                8,
+	GameStateInProgress:                             9,
+	GameStatePostGame:                               10,
+	"DOTA_GAMERULES_STATE_DISCONNECT":               11,
+}
+
+// MatchRecord is what we keep of a finished (or abandoned) match once the
+// next one starts.
+type MatchRecord struct {
+	MatchID   string       `json:"match_id"`
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   time.Time    `json:"ended_at"`
+	LastState string       `json:"last_state"`
+	HeroID    int          `json:"hero_id"`
+	Allies    []int        `json:"allies"`
+	Enemies   []int        `json:"enemies"`
+	Draft     []DraftEntry `json:"draft"`
+	Kills     int          `json:"kills"`
+	Deaths    int          `json:"deaths"`
+	Assists   int          `json:"assists"`
+}
+
+// ObserveMatch feeds the match id and game rules state from GSI or from the
+// "Gamerules: entering state" lines of console.log; either may be empty.
+// A new match starts when the match id changes, or when the game goes back to
+// hero selection (or earlier) after the current match got past it while the
+// current match id is unknown. With a known id a regression is a reconnect
+// to the same match; a new match then shows up as a new id. The old match is
+// archived and every per-match field is reset. Returns true when that
+// happened.
+func (s *GameState) ObserveMatch(matchID, gameState string, at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rank := gameStateOrder[gameState]
+	heroSelection := gameStateOrder[GameStateHeroSelection]
+
+	newMatch := false
+	if matchID != "" && s.matchID != "" && matchID != s.matchID {
+		newMatch = true
+	}
+	if s.matchID == "" && rank > 0 && rank <= heroSelection && s.matchStage > heroSelection {
+		newMatch = true
+	}
+
+	if newMatch {
+		s.archiveMatchLocked(at)
+		s.resetMatchLocked()
+		s.matchStartedAt = at
+		s.notifyLocked()
+	}
+	if s.matchStartedAt.IsZero() {
+		s.matchStartedAt = at
+	}
+
+	if matchID != "" {
+		s.matchID = matchID
+	}
+	if gameState != "" && gameState != s.gameState {
+		s.notifyLocked()
+	}
+	if gameState != "" {
+		s.gameState = gameState
+		if newMatch || rank > s.matchStage {
+			s.matchStage = rank
+		}
+	}
+	return newMatch
+}
+
+// MatchGen counts the per-match resets, so work started for one match can
+// tell that the state has moved on to the next.
+func (s *GameState) MatchGen() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.matchGen
+}
+
+func (s *GameState) MatchHistory() []MatchRecord {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneMatchHistory(s.matchHistory)
+}
+
+func (s *GameState) archiveMatchLocked(at time.Time) {
+	if s.matchID == "" && len(s.enemyHeroesIDs) == 0 && len(s.allyHeroesIDs) == 0 && len(s.draft) == 0 {
+		return
+	}
+	s.matchHistory = append(s.matchHistory, MatchRecord{
+		MatchID:   s.matchID,
+		StartedAt: s.matchStartedAt,
+		EndedAt:   at,
+		LastState: s.gameState,
+		HeroID:    s.gsiHeroID,
+		Allies:    append([]int(nil), s.allyHeroesIDs...),
+		Enemies:   append([]int(nil), s.enemyHeroesIDs...),
+		Draft:     append([]DraftEntry(nil), s.draft...),
+		Kills:     s.gsiKills,
+		Deaths:    s.gsiDeaths,
+		Assists:   s.gsiAssists,
+	})
+	if len(s.matchHistory) > maxMatchHistory {
+		s.matchHistory = s.matchHistory[len(s.matchHistory)-maxMatchHistory:]
+	}
+}
+
+func (s *GameState) resetMatchLocked() {
+	s.matchID = ""
+	s.gameState = ""
+	s.matchStage = 0
+	s.enemyHeroesIDs = nil
+	s.allyHeroesIDs = nil
+	s.lastCounterHero = 0
+	s.counterPicksBy = make(map[int][]CounterPick)
+	s.bestCounters = nil
+	// Analyses still running for the old match must not commit.
+	s.analysisGen++
+	s.matchGen++
+	s.draft = nil
+	s.draftTurnTeam = ""
+	s.draftBanning = false
+	s.banSuggestions = nil
+	s.gsiItems = Items{}
+	s.gsiAbilities = nil
+	s.gsiHeroStatus = HeroStatus{}
+}
+
+func cloneMatchHistory(src []MatchRecord) []MatchRecord {
+	if src == nil {
+		return nil
+	}
+	dst := make([]MatchRecord, 0, len(src))
+	for _, m := range src {
+		m.Allies = append([]int(nil), m.Allies...)
+		m.Enemies = append([]int(nil), m.Enemies...)
+		m.Draft = append([]DraftEntry(nil), m.Draft...)
+		dst = append(dst, m)
+	}
+	return dst
+}
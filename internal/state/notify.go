@@ -0,0 +1,20 @@
+package state
+
+// Changed returns a channel that is closed on the next change to the state.
+// Callers wait on it, take a Snapshot, then ask for a fresh channel.
+func (s *GameState) Changed() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan struct{})
+	}
+	return s.changed
+}
+
+// notifyLocked wakes every Changed waiter. s.mu must be held for writing.
+func (s *GameState) notifyLocked() {
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
@@ -0,0 +1,36 @@
+package state
+
+// SetGSIAccount records the account ID GSI reports for the local player and
+// reports whether it changed. Empty IDs (menus, spectating) are ignored.
+func (s *GameState) SetGSIAccount(accountID string) bool {
+	if accountID == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gsiAccountID == accountID {
+		return false
+	}
+	s.gsiAccountID = accountID
+	s.notifyLocked()
+	return true
+}
+
+// SetHeroPool stores the heroes we actually play.
+func (s *GameState) SetHeroPool(heroIDs []int) {
+	s.mu.Lock()
+	s.heroPool = append([]int(nil), heroIDs...)
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+func (s *GameState) HeroPool() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]int(nil), s.heroPool...)
+}
+
+// InPool reports whether heroID is in the hero pool.
+func (snap Snapshot) InPool(heroID int) bool {
+	return containsInt(snap.HeroPool, heroID)
+}
@@ -0,0 +1,15 @@
+package state
+
+// SetHeroRoles stores each hero's OpenDota roles ("Carry", "Support", ...).
+func (s *GameState) SetHeroRoles(roles map[int][]string) {
+	s.mu.Lock()
+	s.heroRoles = roles
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+func (s *GameState) HeroRoles(heroID int) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.heroRoles[heroID]...)
+}
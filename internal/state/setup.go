@@ -0,0 +1,16 @@
+package state
+
+// SetupCheck mirrors setup.Check for the overlay's checklist.
+type SetupCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix"`
+}
+
+func (s *GameState) SetSetupChecks(checks []SetupCheck) {
+	s.mu.Lock()
+	s.setupChecks = append([]SetupCheck(nil), checks...)
+	s.notifyLocked()
+	s.mu.Unlock()
+}
@@ -7,6 +7,8 @@ import (
 
 type Snapshot struct {
 	EnemyHeroesIDs  []int
+	AllyHeroesIDs   []int
+	GSITeam         string
 	HeroIDToName    map[int]string
 	InternalToID    map[string]int
 	IsLocked        bool
@@ -40,22 +42,40 @@ type Snapshot struct {
 	GSIGoldU        int
 	GSIGPM          int
 	GSIXPM          int
+	GSIItems        Items
+	GSIHeroStatus   HeroStatus
+	GSIAbilities    []Ability
+	Draft           []DraftEntry
+	GameState       string
+	MatchStartedAt  time.Time
+	MatchHistory    []MatchRecord
+	MatchupSource   string
+	GSIAccountID    string
+	HeroPool        []int
+	DraftTurnTeam   string
+	DraftBanning    bool
+	BanSuggestions  []ScoredHero
+	SetupChecks     []SetupCheck
 }
 
 type CounterPick struct {
-	HeroID  int
-	Games   int
-	WinRate float64
+	HeroID     int
+	Games      int
+	WinRate    float64
+	Score      float64
+	Confidence float64
 }
 
 type ScoredHero struct {
-	HeroID int
-	Score  float64
+	HeroID int     `json:"hero_id"`
+	Score  float64 `json:"score"`
 }
 
 type GameState struct {
 	mu              sync.RWMutex
 	enemyHeroesIDs  []int
+	allyHeroesIDs   []int
+	gsiTeam         string
 	heroIDToName    map[int]string
 	internalToID    map[string]int
 	isLocked        bool
@@ -89,6 +109,26 @@ type GameState struct {
 	gsiGoldU        int
 	gsiGPM          int
 	gsiXPM          int
+	changed         chan struct{}
+	gsiItems        Items
+	gsiHeroStatus   HeroStatus
+	gsiAbilities    []Ability
+	draft           []DraftEntry
+	matchID         string
+	gameState       string
+	matchStage      int
+	matchStartedAt  time.Time
+	matchHistory    []MatchRecord
+	matchupSource   string
+	analysisGen     uint64
+	matchGen        uint64
+	heroRoles       map[int][]string
+	gsiAccountID    string
+	heroPool        []int
+	draftTurnTeam   string
+	draftBanning    bool
+	banSuggestions  []ScoredHero
+	setupChecks     []SetupCheck
 }
 
 func NewGameState(internalToID map[string]int, heroIDToName map[int]string) *GameState {
@@ -103,6 +143,7 @@ func (s *GameState) ToggleLocked() bool {
 	s.mu.Lock()
 	s.isLocked = !s.isLocked
 	locked := s.isLocked
+	s.notifyLocked()
 	s.mu.Unlock()
 	return locked
 }
@@ -117,6 +158,7 @@ func (s *GameState) IsLocked() bool {
 func (s *GameState) SetStatus(status string) {
 	s.mu.Lock()
 	s.status = status
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
@@ -126,6 +168,7 @@ func (s *GameState) SetLoading(loading bool, status string) {
 	if status != "" {
 		s.status = status
 	}
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
@@ -136,6 +179,7 @@ func (s *GameState) SetMappings(internalToID map[string]int, heroIDToName map[in
 	if s.counterPicksBy == nil {
 		s.counterPicksBy = make(map[int][]CounterPick)
 	}
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
@@ -147,6 +191,9 @@ func (s *GameState) AddEnemyHeroByInternalName(internal string) (bool, int) {
 	if !ok {
 		return false, 0
 	}
+	if containsInt(s.allyHeroesIDs, id) {
+		return false, id
+	}
 
 	for _, existing := range s.enemyHeroesIDs {
 		if existing == id {
@@ -155,6 +202,7 @@ func (s *GameState) AddEnemyHeroByInternalName(internal string) (bool, int) {
 	}
 
 	s.enemyHeroesIDs = append(s.enemyHeroesIDs, id)
+	s.notifyLocked()
 	return true, id
 }
 
@@ -165,6 +213,9 @@ func (s *GameState) AddEnemyHeroByID(id int) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if containsInt(s.allyHeroesIDs, id) {
+		return false
+	}
 	for _, existing := range s.enemyHeroesIDs {
 		if existing == id {
 			return false
@@ -172,6 +223,7 @@ func (s *GameState) AddEnemyHeroByID(id int) bool {
 	}
 
 	s.enemyHeroesIDs = append(s.enemyHeroesIDs, id)
+	s.notifyLocked()
 	return true
 }
 
@@ -187,22 +239,14 @@ func (s *GameState) AppendOverlayLog(line string, max int) {
 	if max > 0 && len(s.overlayLogs) > max {
 		s.overlayLogs = s.overlayLogs[len(s.overlayLogs)-max:]
 	}
-	s.mu.Unlock()
-}
-
-func (s *GameState) SetHeroCounters(heroID int, picks []CounterPick) {
-	s.mu.Lock()
-	if s.counterPicksBy == nil {
-		s.counterPicksBy = make(map[int][]CounterPick)
-	}
-	s.lastCounterHero = heroID
-	s.counterPicksBy[heroID] = append([]CounterPick(nil), picks...)
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
 func (s *GameState) SetBestCounters(counters []ScoredHero) {
 	s.mu.Lock()
 	s.bestCounters = append([]ScoredHero(nil), counters...)
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
@@ -210,12 +254,23 @@ func (s *GameState) SetGSISeen(at time.Time) {
 	s.mu.Lock()
 	s.gsiLastAt = at
 	s.gsiStatus = "OK"
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+// SetMatchupSource records which matchup source ("live", "bundled", ...)
+// answered the latest counter lookup.
+func (s *GameState) SetMatchupSource(name string) {
+	s.mu.Lock()
+	s.matchupSource = name
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
 func (s *GameState) SetGSIStatus(status string) {
 	s.mu.Lock()
 	s.gsiStatus = status
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
@@ -266,6 +321,7 @@ func (s *GameState) SetGSISnapshot(
 	s.gsiGoldU = goldU
 	s.gsiGPM = gpm
 	s.gsiXPM = xpm
+	s.notifyLocked()
 	s.mu.Unlock()
 }
 
@@ -275,6 +331,8 @@ func (s *GameState) Snapshot(maxLogs int) Snapshot {
 
 	snap := Snapshot{
 		EnemyHeroesIDs:  append([]int(nil), s.enemyHeroesIDs...),
+		AllyHeroesIDs:   append([]int(nil), s.allyHeroesIDs...),
+		GSITeam:         s.gsiTeam,
 		HeroIDToName:    cloneMapIntString(s.heroIDToName),
 		InternalToID:    cloneMapStringInt(s.internalToID),
 		IsLocked:        s.isLocked,
@@ -308,6 +366,20 @@ func (s *GameState) Snapshot(maxLogs int) Snapshot {
 		GSIGoldU:        s.gsiGoldU,
 		GSIGPM:          s.gsiGPM,
 		GSIXPM:          s.gsiXPM,
+		GSIItems:        cloneItems(s.gsiItems),
+		GSIHeroStatus:   cloneHeroStatus(s.gsiHeroStatus),
+		GSIAbilities:    append([]Ability(nil), s.gsiAbilities...),
+		Draft:           append([]DraftEntry(nil), s.draft...),
+		GameState:       s.gameState,
+		MatchStartedAt:  s.matchStartedAt,
+		MatchHistory:    cloneMatchHistory(s.matchHistory),
+		MatchupSource:   s.matchupSource,
+		GSIAccountID:    s.gsiAccountID,
+		HeroPool:        append([]int(nil), s.heroPool...),
+		DraftTurnTeam:   s.draftTurnTeam,
+		DraftBanning:    s.draftBanning,
+		BanSuggestions:  append([]ScoredHero(nil), s.banSuggestions...),
+		SetupChecks:     append([]SetupCheck(nil), s.setupChecks...),
 	}
 
 	if maxLogs > 0 && len(snap.OverlayLogs) > maxLogs {
@@ -0,0 +1,57 @@
+package state
+
+// AddAllyHeroByID records a hero on our team. A hero that was earlier taken
+// for an enemy (e.g. our own pick seen in console.log) is moved over together
+// with its counter table.
+func (s *GameState) AddAllyHeroByID(id int) bool {
+	if id == 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if containsInt(s.allyHeroesIDs, id) {
+		return false
+	}
+	s.allyHeroesIDs = append(s.allyHeroesIDs, id)
+	s.enemyHeroesIDs = removeInt(s.enemyHeroesIDs, id)
+	delete(s.counterPicksBy, id)
+	if s.lastCounterHero == id {
+		s.lastCounterHero = 0
+	}
+	s.notifyLocked()
+	return true
+}
+
+func (s *GameState) AllyHeroes() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]int(nil), s.allyHeroesIDs...)
+}
+
+// SetGSITeam stores our team_name from GSI ("radiant", "dire" or "spectator").
+func (s *GameState) SetGSITeam(team string) {
+	s.mu.Lock()
+	s.gsiTeam = team
+	s.notifyLocked()
+	s.mu.Unlock()
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func removeInt(list []int, v int) []int {
+	out := list[:0]
+	for _, x := range list {
+		if x != v {
+			out = append(out, x)
+		}
+	}
+	return out
+}
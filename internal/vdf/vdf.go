@@ -0,0 +1,385 @@
+// Package vdf reads Valve's KeyValues text format, used by Steam for files
+// like libraryfolders.vdf and appmanifest_*.acf:
+//
+//	"AppState"
+//	{
+//		"appid"      "570"
+//		"installdir" "dota 2 beta"
+//	}
+//
+// Keys and values may be quoted (with \\, \", \n and \t escapes) or bare,
+// and "//" starts a comment. A pair followed (or, for a block, preceded)
+// by a conditional such as [$WIN32] or [!$OSX] is kept only when the
+// condition holds on the platform we run on.
+package vdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+// Pair is one entry of an Object: Key with either a string Value or a
+// nested Object.
+type Pair struct {
+	Key    string
+	Value  string
+	Object *Object
+}
+
+// Object is a block of pairs in file order. Keys may repeat.
+type Object struct {
+	Pairs []Pair
+}
+
+// Get returns the string value of the first pair named key. Keys match
+// case-insensitively, as in Steam.
+func (o *Object) Get(key string) (string, bool) {
+	if o == nil {
+		return "", false
+	}
+	for _, p := range o.Pairs {
+		if p.Object == nil && strings.EqualFold(p.Key, key) {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+// Child returns the first nested object named key, or nil.
+func (o *Object) Child(key string) *Object {
+	if o == nil {
+		return nil
+	}
+	for _, p := range o.Pairs {
+		if p.Object != nil && strings.EqualFold(p.Key, key) {
+			return p.Object
+		}
+	}
+	return nil
+}
+
+// Has reports whether any pair, string or object, is named key.
+func (o *Object) Has(key string) bool {
+	if o == nil {
+		return false
+	}
+	for _, p := range o.Pairs {
+		if strings.EqualFold(p.Key, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads a whole KeyValues document, evaluating conditionals for the
+// platform we run on. The top level is returned as an Object, so a file
+// with one root key has one Pair.
+func Parse(r io.Reader) (*Object, error) {
+	return parse(r, platformConditions(runtime.GOOS, runtime.GOARCH))
+}
+
+func parse(r io.Reader, conds conditions) (*Object, error) {
+	p := &parser{lex: &lexer{r: bufio.NewReader(r), line: 1}, conds: conds}
+	obj, err := p.object(false)
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// conditions are the names that hold in conditionals, upper-case and
+// without the "$".
+type conditions map[string]bool
+
+func platformConditions(goos, goarch string) conditions {
+	conds := conditions{}
+	switch goos {
+	case "windows":
+		conds["WIN32"], conds["WINDOWS"] = true, true
+		if strings.HasSuffix(goarch, "64") {
+			conds["WIN64"] = true
+		}
+	case "darwin":
+		conds["OSX"], conds["POSIX"] = true, true
+	case "linux":
+		conds["LINUX"], conds["POSIX"] = true, true
+	default:
+		conds["POSIX"] = true
+	}
+	return conds
+}
+
+// eval evaluates a conditional like "$WIN32", "!$X360" or
+// "$WIN32||$OSX"; && binds tighter than ||.
+func (c conditions) eval(expr string) bool {
+	for _, alt := range strings.Split(expr, "||") {
+		holds := true
+		for _, term := range strings.Split(alt, "&&") {
+			term = strings.TrimSpace(term)
+			negated := strings.HasPrefix(term, "!")
+			name := strings.ToUpper(strings.TrimPrefix(strings.TrimPrefix(term, "!"), "$"))
+			if c[name] == negated {
+				holds = false
+				break
+			}
+		}
+		if holds {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	lex   *lexer
+	conds conditions
+	// peeked is a token read ahead to look for a trailing conditional.
+	peeked *token
+}
+
+func (p *parser) next() (token, error) {
+	if p.peeked != nil {
+		tok := *p.peeked
+		p.peeked = nil
+		return tok, nil
+	}
+	return p.lex.next()
+}
+
+// object reads pairs until "}" (nested) or the end of input (top level).
+func (p *parser) object(nested bool) (*Object, error) {
+	obj := &Object{}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		switch tok.kind {
+		case tokEOF:
+			if nested {
+				return nil, p.lex.errorf("unexpected end of input, missing }")
+			}
+			return obj, nil
+		case tokClose:
+			if !nested {
+				return nil, p.lex.errorf("unexpected }")
+			}
+			return obj, nil
+		case tokOpen:
+			return nil, p.lex.errorf("unexpected {, expected a key")
+		case tokCond:
+			return nil, p.lex.errorf("unexpected conditional [%s], expected a key", tok.text)
+		}
+
+		key := tok.text
+		val, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		keep := true
+		if val.kind == tokCond {
+			keep = p.conds.eval(val.text)
+			if val, err = p.next(); err != nil {
+				return nil, err
+			}
+		}
+
+		var pair Pair
+		switch val.kind {
+		case tokString:
+			pair = Pair{Key: key, Value: val.text}
+		case tokOpen:
+			child, err := p.object(true)
+			if err != nil {
+				return nil, err
+			}
+			pair = Pair{Key: key, Object: child}
+		default:
+			return nil, p.lex.errorf("key %q has no value", key)
+		}
+
+		after, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if after.kind == tokCond {
+			keep = keep && p.conds.eval(after.text)
+		} else {
+			p.peeked = &after
+		}
+		if keep {
+			obj.Pairs = append(obj.Pairs, pair)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokString
+	tokOpen
+	tokClose
+	// tokCond is a [$CONDITION]; text is what is inside the brackets.
+	tokCond
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+type lexer struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (l *lexer) errorf(format string, args ...any) error {
+	return fmt.Errorf("vdf: line %d: %s", l.line, fmt.Sprintf(format, args...))
+}
+
+func (l *lexer) read() (rune, error) {
+	c, _, err := l.r.ReadRune()
+	if c == '\n' {
+		l.line++
+	}
+	return c, err
+}
+
+func (l *lexer) unread(c rune) {
+	_ = l.r.UnreadRune()
+	if c == '\n' {
+		l.line--
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return token{kind: tokEOF}, nil
+		}
+		if err != nil {
+			return token{}, err
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\uFEFF':
+			continue
+		case c == '{':
+			return token{kind: tokOpen}, nil
+		case c == '}':
+			return token{kind: tokClose}, nil
+		case c == '"':
+			return l.quoted()
+		case c == '[':
+			return l.conditional()
+		case c == '/':
+			n, err := l.read()
+			if err == nil && n == '/' {
+				if err := l.skipUntil('\n'); err != nil && err != io.EOF {
+					return token{}, err
+				}
+				continue
+			}
+			if err == nil {
+				l.unread(n)
+			}
+			return l.bare(c)
+		default:
+			return l.bare(c)
+		}
+	}
+}
+
+func (l *lexer) quoted() (token, error) {
+	var b strings.Builder
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			return token{}, l.errorf("unterminated string")
+		}
+		if err != nil {
+			return token{}, err
+		}
+		switch c {
+		case '"':
+			return token{kind: tokString, text: b.String()}, nil
+		case '\\':
+			e, err := l.read()
+			if err != nil {
+				return token{}, l.errorf("unterminated string")
+			}
+			switch e {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case '\\', '"':
+				b.WriteRune(e)
+			default:
+				// Unknown escapes are kept as written.
+				b.WriteRune('\\')
+				b.WriteRune(e)
+			}
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+// bare reads an unquoted token starting with first.
+func (l *lexer) bare(first rune) (token, error) {
+	var b strings.Builder
+	b.WriteRune(first)
+	for {
+		c, err := l.read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return token{}, err
+		}
+		if strings.ContainsRune(" \t\r\n{}\"", c) {
+			l.unread(c)
+			break
+		}
+		b.WriteRune(c)
+	}
+	return token{kind: tokString, text: b.String()}, nil
+}
+
+func (l *lexer) conditional() (token, error) {
+	var b strings.Builder
+	for {
+		c, err := l.read()
+		if err == io.EOF || c == '\n' {
+			if c == '\n' {
+				l.unread(c)
+			}
+			return token{}, l.errorf("unterminated conditional")
+		}
+		if err != nil {
+			return token{}, err
+		}
+		if c == ']' {
+			return token{kind: tokCond, text: b.String()}, nil
+		}
+		b.WriteRune(c)
+	}
+}
+
+func (l *lexer) skipUntil(end rune) error {
+	for {
+		c, err := l.read()
+		if err != nil {
+			return err
+		}
+		if c == end {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package vdf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var windows = platformConditions("windows", "amd64")
+var linux = platformConditions("linux", "amd64")
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		conds conditions
+		want  *Object
+	}{
+		{
+			name: "nesting",
+			in: `"root"
+{
+	"a"	"1"
+	"child"
+	{
+		"b"	"2"
+		"empty" {}
+	}
+	"c"	"3"
+}`,
+			want: &Object{Pairs: []Pair{{Key: "root", Object: &Object{Pairs: []Pair{
+				{Key: "a", Value: "1"},
+				{Key: "child", Object: &Object{Pairs: []Pair{
+					{Key: "b", Value: "2"},
+					{Key: "empty", Object: &Object{}},
+				}}},
+				{Key: "c", Value: "3"},
+			}}}}},
+		},
+		{
+			name: "escapes",
+			in:   `"k" "quote \" backslash \\ newline \n tab \t unknown \q"`,
+			want: &Object{Pairs: []Pair{{Key: "k", Value: "quote \" backslash \\ newline \n tab \t unknown \\q"}}},
+		},
+		{
+			name: "comments",
+			in: `// header comment
+"k" "v" // trailing comment
+// "hidden" "pair"
+"path" "a//b"`,
+			want: &Object{Pairs: []Pair{{Key: "k", Value: "v"}, {Key: "path", Value: "a//b"}}},
+		},
+		{
+			name: "bare tokens and BOM",
+			in:   "\uFEFFkey value\r\nother/path {x 1}",
+			want: &Object{Pairs: []Pair{
+				{Key: "key", Value: "value"},
+				{Key: "other/path", Object: &Object{Pairs: []Pair{{Key: "x", Value: "1"}}}},
+			}},
+		},
+		{
+			name: "repeated keys",
+			in:   `"k" "1" "k" "2"`,
+			want: &Object{Pairs: []Pair{{Key: "k", Value: "1"}, {Key: "k", Value: "2"}}},
+		},
+		{
+			name: "conditionals on windows",
+			in: `"k" "win" [$WIN32]
+"k" "other" [!$WIN32]
+"both" "yes" [$WIN32||$OSX]
+"block" [$LINUX] { "x" "1" }
+"last" "1"`,
+			conds: windows,
+			want: &Object{Pairs: []Pair{
+				{Key: "k", Value: "win"},
+				{Key: "both", Value: "yes"},
+				{Key: "last", Value: "1"},
+			}},
+		},
+		{
+			name: "conditionals on linux",
+			in: `"k" "win" [$WIN32]
+"k" "other" [!$WIN32]
+"both" "yes" [$WIN32||$OSX]
+"block" [$LINUX] { "x" "1" }
+"posix" { "y" "2" } [$POSIX && !$X360]`,
+			conds: linux,
+			want: &Object{Pairs: []Pair{
+				{Key: "k", Value: "other"},
+				{Key: "block", Object: &Object{Pairs: []Pair{{Key: "x", Value: "1"}}}},
+				{Key: "posix", Object: &Object{Pairs: []Pair{{Key: "y", Value: "2"}}}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parse(strings.NewReader(tt.in), tt.conds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"unterminated string", "\"k\" \"v", "vdf: line 1: unterminated string"},
+		{"unterminated escape", `"k" "v\`, "vdf: line 1: unterminated string"},
+		{"missing close", "\"a\"\n{\n\"b\" \"1\"\n", "vdf: line 4: unexpected end of input, missing }"},
+		{"stray close", "\"a\" \"1\"\n}", "vdf: line 2: unexpected }"},
+		{"open without key", "{ }", "vdf: line 1: unexpected {, expected a key"},
+		{"key without value", "\"a\" { \"b\" }", `vdf: line 1: key "b" has no value`},
+		{"unterminated conditional", "\"a\" \"1\" [$WIN32", "vdf: line 1: unterminated conditional"},
+		{"conditional across lines", "\"a\" \"1\" [$WIN32\n]", "vdf: line 1: unterminated conditional"},
+		{"conditional without key", "[$WIN32] \"a\" \"1\"", "vdf: line 1: unexpected conditional [$WIN32], expected a key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(strings.NewReader(tt.in), linux)
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetChildHas(t *testing.T) {
+	doc, err := parse(strings.NewReader(`"Root" { "Name" "x" "Sub" { "k" "v" } }`), linux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := doc.Child("root")
+	if v, ok := root.Get("NAME"); !ok || v != "x" {
+		t.Errorf(`Get("NAME") = %q, %v`, v, ok)
+	}
+	if _, ok := root.Get("sub"); ok {
+		t.Error(`Get("sub") found a block`)
+	}
+	if v, _ := root.Child("SUB").Get("K"); v != "v" {
+		t.Errorf(`Child("SUB").Get("K") = %q`, v)
+	}
+	if !root.Has("sub") || root.Has("missing") {
+		t.Error("Has is wrong")
+	}
+	var missing *Object
+	if missing.Child("x") != nil || missing.Has("x") {
+		t.Error("nil Object should be empty")
+	}
+}